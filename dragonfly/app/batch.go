@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// batchCommand is one entry in a /pipeline or /tx request body, e.g.
+// {"op":"set","key":"a","value":"1"} or {"op":"incr","key":"b"}.
+type batchCommand struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Field string `json:"field"`
+	TTL   int    `json:"ttl"`
+	Start int64  `json:"start"`
+	Stop  int64  `json:"stop"`
+}
+
+// batchResult reports one command's outcome, in request order.
+type batchResult struct {
+	Op      string      `json:"op"`
+	Key     string      `json:"key"`
+	Success bool        `json:"success"`
+	Value   interface{} `json:"value,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// pipelineHandler executes a batch of commands with Pipeline(): one round
+// trip, no atomicity guarantee across commands.
+func pipelineHandler(w http.ResponseWriter, r *http.Request) {
+	runBatchHandler(w, r, false)
+}
+
+// txHandler executes a batch of commands with TxPipeline(): one round trip,
+// wrapped in MULTI/EXEC so no other client's commands run in between. A
+// command that fails at run time does not roll back the others, and an
+// unknown op is reported as failed without being queued, so the rest still
+// apply.
+func txHandler(w http.ResponseWriter, r *http.Request) {
+	runBatchHandler(w, r, true)
+}
+
+func runBatchHandler(w http.ResponseWriter, r *http.Request, transactional bool) {
+	var cmds []batchCommand
+	if err := json.NewDecoder(r.Body).Decode(&cmds); err != nil {
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "request body must be a JSON array of commands",
+		})
+		return
+	}
+	if len(cmds) == 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"error":   "at least one command is required",
+		})
+		return
+	}
+
+	var pipe redis.Pipeliner
+	if transactional {
+		pipe = sentinelClient.TxPipeline()
+	} else {
+		pipe = sentinelClient.Pipeline()
+	}
+
+	cmders := make([]redis.Cmder, len(cmds))
+	for i, cmd := range cmds {
+		cmders[i] = queueBatchCommand(pipe, cmd)
+	}
+
+	// Exec's own error just reflects whether any queued command failed; the
+	// per-command errors below are what callers actually need.
+	pipe.Exec(ctx)
+
+	results := make([]batchResult, len(cmds))
+	var completed, successful, failed, reads, writes int64
+	for i, cmd := range cmds {
+		results[i] = batchResultFor(cmd, cmders[i])
+		completed++
+		if results[i].Success {
+			successful++
+		} else {
+			failed++
+		}
+		if isWriteOp(cmd.Op) {
+			writes++
+		} else {
+			reads++
+		}
+	}
+
+	atomic.AddInt64(&stats.TotalRequests, completed)
+	atomic.AddInt64(&stats.SuccessfulRequests, successful)
+	atomic.AddInt64(&stats.FailedRequests, failed)
+	atomic.AddInt64(&stats.Reads, reads)
+	atomic.AddInt64(&stats.Writes, writes)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success":       failed == 0,
+		"transactional": transactional,
+		"count":         len(cmds),
+		"results":       results,
+	})
+}
+
+// queueBatchCommand queues one command onto pipe and returns its Cmder for
+// later inspection, once the whole batch has been Exec'd.
+func queueBatchCommand(pipe redis.Cmdable, cmd batchCommand) redis.Cmder {
+	switch cmd.Op {
+	case "set":
+		ttl := cmd.TTL
+		if ttl == 0 {
+			ttl = 300
+		}
+		return queueSet(pipe, cmd.Key, cmd.Value, ttl)
+	case "incr":
+		return queueIncr(pipe, cmd.Key)
+	case "lpush":
+		return queueLPush(pipe, cmd.Key, cmd.Value)
+	case "sadd":
+		return queueSAdd(pipe, cmd.Key, cmd.Value)
+	case "hset":
+		return queueHSet(pipe, cmd.Key, cmd.Field, cmd.Value)
+	case "get":
+		return queueGet(pipe, cmd.Key)
+	case "exists":
+		return queueExists(pipe, cmd.Key)
+	case "lrange":
+		stop := cmd.Stop
+		if stop == 0 {
+			stop = 10
+		}
+		return queueLRange(pipe, cmd.Key, cmd.Start, stop)
+	case "smembers":
+		return queueSMembers(pipe, cmd.Key)
+	case "hgetall":
+		return queueHGetAll(pipe, cmd.Key)
+	default:
+		// Not a real command, so don't queue anything onto pipe for it: just
+		// hand back a Cmder that's already failed, so batchResultFor reports
+		// the typo instead of a disguised no-op success.
+		errCmd := redis.NewStatusCmd(ctx)
+		errCmd.SetErr(fmt.Errorf("unknown op %q", cmd.Op))
+		return errCmd
+	}
+}
+
+// batchResultFor reads back a queued, now-executed Cmder's outcome.
+func batchResultFor(cmd batchCommand, cmder redis.Cmder) batchResult {
+	res := batchResult{Op: cmd.Op, Key: cmd.Key}
+
+	if err := cmder.Err(); err != nil && err != redis.Nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	res.Success = true
+	switch c := cmder.(type) {
+	case *redis.StatusCmd:
+		res.Value = c.Val()
+	case *redis.IntCmd:
+		res.Value = c.Val()
+	case *redis.StringCmd:
+		if c.Err() != redis.Nil {
+			res.Value = c.Val()
+		}
+	case *redis.StringSliceCmd:
+		res.Value = c.Val()
+	case *redis.MapStringStringCmd:
+		res.Value = c.Val()
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SentinelEvent is one failover-related pub/sub message, as streamed to
+// /events and recorded in the /events/history ring buffer.
+type SentinelEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Channel   string    `json:"channel"`
+	Payload   string    `json:"payload"`
+}
+
+// eventRing is a bounded FIFO history of the most recent Sentinel events.
+type eventRing struct {
+	mu       sync.Mutex
+	items    []SentinelEvent
+	capacity int
+}
+
+func newEventRing(capacity int) *eventRing {
+	return &eventRing{capacity: capacity}
+}
+
+func (r *eventRing) Push(e SentinelEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = append(r.items, e)
+	if len(r.items) > r.capacity {
+		r.items = r.items[len(r.items)-r.capacity:]
+	}
+}
+
+func (r *eventRing) Snapshot() []SentinelEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]SentinelEvent, len(r.items))
+	copy(out, r.items)
+	return out
+}
+
+var (
+	eventHistory = newEventRing(500)
+
+	eventSubsMu sync.Mutex
+	eventSubs   = make(map[chan SentinelEvent]struct{})
+)
+
+// publishEvent records e in the history ring and fans it out to every
+// live /events subscriber. Subscribers that aren't keeping up have the
+// event dropped for them rather than blocking the publisher.
+func publishEvent(e SentinelEvent) {
+	eventHistory.Push(e)
+
+	eventSubsMu.Lock()
+	defer eventSubsMu.Unlock()
+	for ch := range eventSubs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func subscribeEvents() chan SentinelEvent {
+	ch := make(chan SentinelEvent, 16)
+	eventSubsMu.Lock()
+	eventSubs[ch] = struct{}{}
+	eventSubsMu.Unlock()
+	return ch
+}
+
+func unsubscribeEvents(ch chan SentinelEvent) {
+	eventSubsMu.Lock()
+	delete(eventSubs, ch)
+	eventSubsMu.Unlock()
+}
+
+// watchSentinelEventStream subscribes to the Sentinel failover channels on
+// every configured address and publishes each message as a SentinelEvent.
+// +switch-master/+sdown/-sdown/+odown are exact channels; the various
+// +failover-state-*/+failover-end/+failover-abort-* channels are covered
+// with a single +failover-* pattern subscription.
+func watchSentinelEventStream(sentinelAddrs []string) {
+	for _, addr := range sentinelAddrs {
+		go func(addr string) {
+			sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr})
+			defer sentinel.Close()
+
+			sub := sentinel.Subscribe(ctx, "+switch-master", "+sdown", "-sdown", "+odown")
+			defer sub.Close()
+
+			psub := sentinel.PSubscribe(ctx, "+failover-*")
+			defer psub.Close()
+
+			for {
+				select {
+				case msg, ok := <-sub.Channel():
+					if !ok {
+						return
+					}
+					publishEvent(SentinelEvent{Timestamp: time.Now(), Source: addr, Channel: msg.Channel, Payload: msg.Payload})
+				case msg, ok := <-psub.Channel():
+					if !ok {
+						return
+					}
+					publishEvent(SentinelEvent{Timestamp: time.Now(), Source: addr, Channel: msg.Channel, Payload: msg.Payload})
+				}
+			}
+		}(addr)
+	}
+}
+
+// eventsHandler streams Sentinel failover events to the client as
+// Server-Sent Events, one JSON-encoded SentinelEvent per message.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := subscribeEvents()
+	defer unsubscribeEvents(ch)
+
+	for {
+		select {
+		case e := <-ch:
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// eventsHistoryHandler returns the buffered recent Sentinel events without
+// requiring a held-open /events connection.
+func eventsHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	events := eventHistory.Snapshot()
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"count":  len(events),
+		"events": events,
+	})
+}
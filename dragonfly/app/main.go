@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -81,13 +82,21 @@ func main() {
 		log.Println("Connected to DragonflyDB via Sentinel")
 	}
 
+	setupReadClient(sentinelAddrs, masterName)
+	log.Printf("Read route mode: %s", routeMode)
+
+	go watchSentinelFailover(sentinelAddrs, masterName)
+	go watchSentinelEventStream(sentinelAddrs)
+
 	// Setup router
 	r := mux.NewRouter()
 	r.Use(loggingMiddleware)
+	r.Use(metricsMiddleware)
 
 	// API Routes
 	r.HandleFunc("/health", healthHandler).Methods("GET")
 	r.HandleFunc("/stats", statsHandler).Methods("GET")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 	r.HandleFunc("/stats/reset", resetStatsHandler).Methods("POST")
 	r.HandleFunc("/set", setHandler).Methods("POST")
 	r.HandleFunc("/incr", incrHandler).Methods("POST")
@@ -104,6 +113,10 @@ func main() {
 	r.HandleFunc("/smembers", smembersHandler).Methods("GET")
 	r.HandleFunc("/hgetall/{key}", hgetallHandler).Methods("GET")
 	r.HandleFunc("/hgetall", hgetallHandler).Methods("GET")
+	r.HandleFunc("/pipeline", pipelineHandler).Methods("POST")
+	r.HandleFunc("/tx", txHandler).Methods("POST")
+	r.HandleFunc("/events", eventsHandler).Methods("GET")
+	r.HandleFunc("/events/history", eventsHistoryHandler).Methods("GET")
 	r.HandleFunc("/load", loadHandler).Methods("POST")
 
 	// Start server
@@ -117,6 +130,7 @@ func main() {
 		log.Println("Available endpoints:")
 		log.Println("  GET  /health - Health check")
 		log.Println("  GET  /stats - View statistics")
+		log.Println("  GET  /metrics - Prometheus metrics")
 		log.Println("  POST /stats/reset - Reset statistics")
 		log.Println("  POST /set - SET operation")
 		log.Println("  POST /incr - INCR operation")
@@ -128,6 +142,10 @@ func main() {
 		log.Println("  GET  /lrange/:key? - LRANGE operation")
 		log.Println("  GET  /smembers/:key? - SMEMBERS operation")
 		log.Println("  GET  /hgetall/:key? - HGETALL operation")
+		log.Println("  POST /pipeline - Execute a batch of commands via Pipeline()")
+		log.Println("  POST /tx - Execute a batch of commands via TxPipeline()")
+		log.Println("  GET  /events - Stream Sentinel failover events (SSE)")
+		log.Println("  GET  /events/history - Recent Sentinel failover events")
 		log.Println("  POST /load - Generate mixed load")
 
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -162,7 +180,9 @@ func loggingMiddleware(next http.Handler) http.Handler {
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
-	err := sentinelClient.Set(ctx, "_health_check", "ok", 10*time.Second).Err()
+	err := resilientOp("write", "health_set", func() error {
+		return sentinelClient.Set(ctx, "_health_check", "ok", 10*time.Second).Err()
+	})
 	if err != nil {
 		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"status": "unhealthy",
@@ -171,7 +191,9 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	val, err := sentinelClient.Get(ctx, "_health_check").Result()
+	val, err := resilientOpT("read", "health_get", func() (string, error) {
+		return sentinelClient.Get(ctx, "_health_check").Result()
+	})
 	if err != nil {
 		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"status": "unhealthy",
@@ -192,6 +214,13 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// statsHandler reports aggregate request counters. These are also mirrored
+// into the Prometheus registry as requestStats* (metrics.go), via a live
+// callback onto this same stats struct rather than a second counter that
+// could drift from it, so /metrics and /stats always agree. Per-operation
+// latency and error-rate breakdowns are tracked by the same timedOp/timedOpT
+// call sites that feed this struct, but are exposed in full at /metrics
+// rather than duplicated here.
 func statsHandler(w http.ResponseWriter, r *http.Request) {
 	uptime := float64(time.Now().Unix() - stats.StartTime)
 	total := atomic.LoadInt64(&stats.TotalRequests)
@@ -217,6 +246,9 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 		"uptime":             fmt.Sprintf("%.2fs", uptime),
 		"requestsPerSecond":  fmt.Sprintf("%.2f", requestsPerSecond),
 		"successRate":        fmt.Sprintf("%.2f%%", successRate),
+		"readRouteMode":      routeMode,
+		"servedBy":           servedBySnapshot(),
+		"resilience":         resilienceSnapshot(),
 	})
 }
 
@@ -260,13 +292,12 @@ func setHandler(w http.ResponseWriter, r *http.Request) {
 		req.TTL = 300
 	}
 
-	err := sentinelClient.Set(ctx, req.Key, req.Value, time.Duration(req.TTL)*time.Second).Err()
+	err := resilientOp("write", "set", func() error {
+		return queueSet(sentinelClient, req.Key, req.Value, req.TTL).Err()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "write")
 		return
 	}
 
@@ -296,13 +327,12 @@ func incrHandler(w http.ResponseWriter, r *http.Request) {
 		req.Key = fmt.Sprintf("counter:%d", getRandomInt(1, 100))
 	}
 
-	result, err := sentinelClient.Incr(ctx, req.Key).Result()
+	result, err := resilientOpT("write", "incr", func() (int64, error) {
+		return queueIncr(sentinelClient, req.Key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "write")
 		return
 	}
 
@@ -336,17 +366,18 @@ func lpushHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	err := sentinelClient.LPush(ctx, req.Key, req.Value).Err()
+	err := resilientOp("write", "lpush", func() error {
+		return queueLPush(sentinelClient, req.Key, req.Value).Err()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "write")
 		return
 	}
 
-	sentinelClient.LTrim(ctx, req.Key, 0, 99)
+	resilientOp("write", "ltrim", func() error {
+		return sentinelClient.LTrim(ctx, req.Key, 0, 99).Err()
+	})
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -378,13 +409,12 @@ func saddHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	result, err := sentinelClient.SAdd(ctx, req.Key, req.Value).Result()
+	result, err := resilientOpT("write", "sadd", func() (int64, error) {
+		return queueSAdd(sentinelClient, req.Key, req.Value).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "write")
 		return
 	}
 
@@ -424,13 +454,12 @@ func hsetHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	result, err := sentinelClient.HSet(ctx, req.Key, req.Field, req.Value).Result()
+	result, err := resilientOpT("write", "hset", func() (int64, error) {
+		return queueHSet(sentinelClient, req.Key, req.Field, req.Value).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "write")
 		return
 	}
 
@@ -455,13 +484,15 @@ func getHandler(w http.ResponseWriter, r *http.Request) {
 		key = generateRandomKey()
 	}
 
-	value, err := sentinelClient.Get(ctx, key).Result()
+	client, fromMaster := selectReadClient(r)
+	recordServedBy("get", fromMaster)
+
+	value, err := resilientOpT("read", "get", func() (string, error) {
+		return queueGet(client, key).Result()
+	})
 	if err != nil && err != redis.Nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "read")
 		return
 	}
 
@@ -485,13 +516,15 @@ func existsHandler(w http.ResponseWriter, r *http.Request) {
 		key = generateRandomKey()
 	}
 
-	exists, err := sentinelClient.Exists(ctx, key).Result()
+	client, fromMaster := selectReadClient(r)
+	recordServedBy("exists", fromMaster)
+
+	exists, err := resilientOpT("read", "exists", func() (int64, error) {
+		return queueExists(client, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "read")
 		return
 	}
 
@@ -527,13 +560,15 @@ func lrangeHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	values, err := sentinelClient.LRange(ctx, key, int64(start), int64(stop)).Result()
+	client, fromMaster := selectReadClient(r)
+	recordServedBy("lrange", fromMaster)
+
+	values, err := resilientOpT("read", "lrange", func() ([]string, error) {
+		return queueLRange(client, key, int64(start), int64(stop)).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "read")
 		return
 	}
 
@@ -559,13 +594,15 @@ func smembersHandler(w http.ResponseWriter, r *http.Request) {
 		key = fmt.Sprintf("set:%d", getRandomInt(1, 50))
 	}
 
-	members, err := sentinelClient.SMembers(ctx, key).Result()
+	client, fromMaster := selectReadClient(r)
+	recordServedBy("smembers", fromMaster)
+
+	members, err := resilientOpT("read", "smembers", func() ([]string, error) {
+		return queueSMembers(client, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "read")
 		return
 	}
 
@@ -589,13 +626,15 @@ func hgetallHandler(w http.ResponseWriter, r *http.Request) {
 		key = fmt.Sprintf("hash:%d", getRandomInt(1, 50))
 	}
 
-	hash, err := sentinelClient.HGetAll(ctx, key).Result()
+	client, fromMaster := selectReadClient(r)
+	recordServedBy("hgetall", fromMaster)
+
+	hash, err := resilientOpT("read", "hgetall", func() (map[string]string, error) {
+		return queueHGetAll(client, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondResilienceError(w, err, "read")
 		return
 	}
 
@@ -609,97 +648,65 @@ func hgetallHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// loadHandler runs a YCSB-style workload against sentinelClient: a named
+// profile (or a custom op mix) picks the read/write ratio, and a key
+// distribution picks which keys within the keyspace get hit. See
+// workload.go for the profile/distribution implementations.
 func loadHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Operations     int `json:"operations"`
-		ReadWriteRatio int `json:"readWriteRatio"`
+		Profile      string         `json:"profile"`
+		OpMix        map[string]int `json:"opMix"`
+		Concurrency  int            `json:"concurrency"`
+		Duration     int            `json:"duration"`
+		KeyspaceSize int            `json:"keyspaceSize"`
+		ValueSize    int            `json:"valueSize"`
+		Distribution string         `json:"distribution"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		req.Operations = 100
-		req.ReadWriteRatio = 70
-	}
-
-	if req.Operations == 0 {
-		req.Operations = 100
-	}
-	if req.ReadWriteRatio == 0 {
-		req.ReadWriteRatio = 70
-	}
-
-	results := map[string]int{
-		"requested":  req.Operations,
-		"completed":  0,
-		"successful": 0,
-		"failed":     0,
-		"reads":      0,
-		"writes":     0,
-	}
-
-	writeOps := []string{"set", "incr", "lpush", "sadd", "hset"}
-	readOps := []string{"get", "exists", "lrange", "smembers", "hgetall"}
-
-	for i := 0; i < req.Operations; i++ {
-		isRead := rand.Intn(100) < req.ReadWriteRatio
-
-		var err error
-		if isRead {
-			results["reads"]++
-			op := readOps[rand.Intn(len(readOps))]
-			switch op {
-			case "get":
-				_, err = sentinelClient.Get(ctx, generateRandomKey()).Result()
-				if err == redis.Nil {
-					err = nil
-				}
-			case "exists":
-				_, err = sentinelClient.Exists(ctx, generateRandomKey()).Result()
-			case "lrange":
-				_, err = sentinelClient.LRange(ctx, fmt.Sprintf("list:%d", getRandomInt(1, 50)), 0, 10).Result()
-			case "smembers":
-				_, err = sentinelClient.SMembers(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50))).Result()
-			case "hgetall":
-				_, err = sentinelClient.HGetAll(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50))).Result()
-			}
-		} else {
-			results["writes"]++
-			op := writeOps[rand.Intn(len(writeOps))]
-			switch op {
-			case "set":
-				err = sentinelClient.Set(ctx, generateRandomKey(), generateRandomString(20), 300*time.Second).Err()
-			case "incr":
-				_, err = sentinelClient.Incr(ctx, fmt.Sprintf("counter:%d", getRandomInt(1, 100))).Result()
-			case "lpush":
-				listKey := fmt.Sprintf("list:%d", getRandomInt(1, 50))
-				err = sentinelClient.LPush(ctx, listKey, generateRandomString(20)).Err()
-				if err == nil {
-					sentinelClient.LTrim(ctx, listKey, 0, 99)
-				}
-			case "sadd":
-				_, err = sentinelClient.SAdd(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50)), generateRandomString(20)).Result()
-			case "hset":
-				_, err = sentinelClient.HSet(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50)), generateRandomString(10), generateRandomString(20)).Result()
-			}
-		}
+	json.NewDecoder(r.Body).Decode(&req)
 
-		if err != nil {
-			results["failed"]++
-		} else {
-			results["successful"]++
-		}
-		results["completed"]++
+	if req.Profile == "" {
+		req.Profile = "ycsb-a"
+	}
+	if req.Concurrency <= 0 {
+		req.Concurrency = 4
+	}
+	if req.Duration <= 0 {
+		req.Duration = 10
+	}
+	if req.KeyspaceSize <= 0 {
+		req.KeyspaceSize = 1000
 	}
+	if req.ValueSize <= 0 {
+		req.ValueSize = 20
+	}
+	if req.Distribution == "" {
+		req.Distribution = "uniform"
+	}
+
+	ops, weights := buildWorkloadOpMix(req.Profile, req.OpMix)
+
+	result := runWorkload(workloadConfig{
+		profile:      req.Profile,
+		distribution: req.Distribution,
+		concurrency:  req.Concurrency,
+		duration:     time.Duration(req.Duration) * time.Second,
+		keyspaceSize: req.KeyspaceSize,
+		valueSize:    req.ValueSize,
+		ops:          ops,
+		weights:      weights,
+	})
 
-	atomic.AddInt64(&stats.TotalRequests, int64(results["completed"]))
-	atomic.AddInt64(&stats.SuccessfulRequests, int64(results["successful"]))
-	atomic.AddInt64(&stats.FailedRequests, int64(results["failed"]))
-	atomic.AddInt64(&stats.Reads, int64(results["reads"]))
-	atomic.AddInt64(&stats.Writes, int64(results["writes"]))
+	atomic.AddInt64(&stats.TotalRequests, result.Completed)
+	atomic.AddInt64(&stats.SuccessfulRequests, result.Successful)
+	atomic.AddInt64(&stats.FailedRequests, result.Failed)
+	atomic.AddInt64(&stats.Reads, result.Reads)
+	atomic.AddInt64(&stats.Writes, result.Writes)
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Load generation completed",
-		"results": results,
+		"message": "Workload completed",
+		"results": result,
 	})
 }
 
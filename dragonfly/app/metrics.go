@@ -0,0 +1,180 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ha_redis_operation_duration_seconds",
+		Help:    "Latency of Redis operations, labeled by operation and result.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"op", "result"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ha_redis_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests, labeled by method, path, and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	masterInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ha_redis_master_info",
+		Help: "Set to 1 for the currently Sentinel-reported master address, 0 for addresses it failed over from.",
+	}, []string{"addr"})
+
+	failoverTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ha_redis_failover_total",
+		Help: "Count of +switch-master events observed on the Sentinel pub/sub channel.",
+	})
+
+	lastMasterAddrMu sync.Mutex
+	lastMasterAddr   string
+
+	// requestStats* expose the stats struct's aggregate counters through the
+	// same Prometheus registry /metrics serves, via a live callback onto
+	// stats itself rather than a second, independently-updated counter set.
+	// That keeps statsHandler and /metrics reading from one underlying
+	// source of truth, so resetStatsHandler zeroing stats is all it takes to
+	// zero both.
+	requestStatsTotal = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ha_redis_stats_requests_total",
+		Help: "Mirrors the /stats totalRequests counter.",
+	}, func() float64 { return float64(atomic.LoadInt64(&stats.TotalRequests)) })
+
+	requestStatsSuccessful = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ha_redis_stats_requests_successful",
+		Help: "Mirrors the /stats successfulRequests counter.",
+	}, func() float64 { return float64(atomic.LoadInt64(&stats.SuccessfulRequests)) })
+
+	requestStatsFailed = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ha_redis_stats_requests_failed",
+		Help: "Mirrors the /stats failedRequests counter.",
+	}, func() float64 { return float64(atomic.LoadInt64(&stats.FailedRequests)) })
+
+	requestStatsReads = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ha_redis_stats_requests_reads",
+		Help: "Mirrors the /stats reads counter.",
+	}, func() float64 { return float64(atomic.LoadInt64(&stats.Reads)) })
+
+	requestStatsWrites = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ha_redis_stats_requests_writes",
+		Help: "Mirrors the /stats writes counter.",
+	}, func() float64 { return float64(atomic.LoadInt64(&stats.Writes)) })
+)
+
+// timedOp runs fn, classifies its error as ok/error/redis_nil, and records
+// the duration histogram for op.
+func timedOp(op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	observeOp(op, err, time.Since(start))
+	return err
+}
+
+// timedOpT is timedOp for calls that also return a value.
+func timedOpT[T any](op string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	v, err := fn()
+	observeOp(op, err, time.Since(start))
+	return v, err
+}
+
+func observeOp(op string, err error, d time.Duration) {
+	result := "ok"
+	switch {
+	case err != nil && err != redis.Nil:
+		result = "error"
+	case err == redis.Nil:
+		result = "redis_nil"
+	}
+	operationDuration.WithLabelValues(op, result).Observe(d.Seconds())
+}
+
+// metricsMiddleware wraps next so every HTTP request's method/path/status
+// and duration are recorded alongside the Redis operation metrics above.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		httpRequestDuration.WithLabelValues(r.Method, r.URL.Path, strconv.Itoa(sw.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusWriter captures the status code passed to WriteHeader, since
+// http.ResponseWriter doesn't expose it after the fact.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes through to the wrapped writer, so streaming handlers such as
+// /events still see an http.Flusher behind this middleware.
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// watchSentinelFailover subscribes to +switch-master on every Sentinel
+// address and keeps masterInfo/failoverTotal in sync as failovers happen.
+func watchSentinelFailover(sentinelAddrs []string, masterName string) {
+	refreshMasterInfo(sentinelAddrs, masterName)
+
+	for _, addr := range sentinelAddrs {
+		go func(addr string) {
+			sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr})
+			defer sentinel.Close()
+
+			sub := sentinel.Subscribe(ctx, "+switch-master")
+			defer sub.Close()
+
+			for msg := range sub.Channel() {
+				log.Printf("Sentinel failover event from %s: %s", addr, msg.Payload)
+				failoverTotal.Inc()
+				refreshMasterInfo(sentinelAddrs, masterName)
+			}
+		}(addr)
+	}
+}
+
+// refreshMasterInfo queries the first reachable sentinel for the current
+// master address and updates masterInfo, zeroing out the previous address.
+// Guarded by lastMasterAddrMu since this runs concurrently from one
+// goroutine per configured Sentinel address.
+func refreshMasterInfo(sentinelAddrs []string, masterName string) {
+	for _, addr := range sentinelAddrs {
+		sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr, DialTimeout: 5 * time.Second})
+		masterAddr, err := sentinel.GetMasterAddrByName(ctx, masterName).Result()
+		sentinel.Close()
+		if err != nil {
+			continue
+		}
+
+		addrStr := masterAddr[0] + ":" + masterAddr[1]
+
+		lastMasterAddrMu.Lock()
+		if lastMasterAddr != "" && lastMasterAddr != addrStr {
+			masterInfo.WithLabelValues(lastMasterAddr).Set(0)
+		}
+		masterInfo.WithLabelValues(addrStr).Set(1)
+		lastMasterAddr = addrStr
+		lastMasterAddrMu.Unlock()
+		return
+	}
+	log.Printf("Failed to refresh master info: no reachable sentinel")
+}
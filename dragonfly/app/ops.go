@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The queue* functions wrap each operation's go-redis call behind
+// redis.Cmdable rather than the concrete *redis.Client, so the same logic
+// can run either directly against sentinelClient or readClient (the
+// single-op handlers)
+// or queued onto a redis.Pipeliner for batched/transactional execution (see
+// batch.go). Cmdable methods queue instead of executing when called on a
+// pipeline, so callers must not inspect the returned Cmd's error/result
+// until after any necessary Exec.
+
+func queueSet(c redis.Cmdable, key, value string, ttlSeconds int) *redis.StatusCmd {
+	return c.Set(ctx, key, value, time.Duration(ttlSeconds)*time.Second)
+}
+
+func queueIncr(c redis.Cmdable, key string) *redis.IntCmd {
+	return c.Incr(ctx, key)
+}
+
+func queueLPush(c redis.Cmdable, key, value string) *redis.IntCmd {
+	return c.LPush(ctx, key, value)
+}
+
+func queueSAdd(c redis.Cmdable, key, value string) *redis.IntCmd {
+	return c.SAdd(ctx, key, value)
+}
+
+func queueHSet(c redis.Cmdable, key, field, value string) *redis.IntCmd {
+	return c.HSet(ctx, key, field, value)
+}
+
+func queueGet(c redis.Cmdable, key string) *redis.StringCmd {
+	return c.Get(ctx, key)
+}
+
+func queueExists(c redis.Cmdable, key string) *redis.IntCmd {
+	return c.Exists(ctx, key)
+}
+
+func queueLRange(c redis.Cmdable, key string, start, stop int64) *redis.StringSliceCmd {
+	return c.LRange(ctx, key, start, stop)
+}
+
+func queueSMembers(c redis.Cmdable, key string) *redis.StringSliceCmd {
+	return c.SMembers(ctx, key)
+}
+
+func queueHGetAll(c redis.Cmdable, key string) *redis.MapStringStringCmd {
+	return c.HGetAll(ctx, key)
+}
+
+// isWriteOp reports whether op mutates data, as opposed to reading it.
+func isWriteOp(op string) bool {
+	switch op {
+	case "set", "incr", "lpush", "sadd", "hset":
+		return true
+	default:
+		return false
+	}
+}
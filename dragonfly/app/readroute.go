@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// readClient serves replica-routed reads. In "master" route mode it's
+	// the same FailoverClient as sentinelClient; in "random"/"latency" mode
+	// it's a FailoverClusterClient, which is itself Sentinel-aware and picks
+	// a node per Redis command.
+	readClient redis.Cmdable
+	routeMode  string
+
+	servedByMu    sync.Mutex
+	servedByCount = map[string]*endpointServed{
+		"get":      {},
+		"exists":   {},
+		"lrange":   {},
+		"smembers": {},
+		"hgetall":  {},
+	}
+)
+
+type endpointServed struct {
+	master  int64
+	replica int64
+}
+
+// setupReadClient builds readClient (reads, routed per SENTINEL_ROUTE_MODE)
+// from the same Sentinel addresses/master name as sentinelClient (writes,
+// always routed to master). In "master" mode readClient is sentinelClient
+// itself, so sentinelClient must already be set.
+func setupReadClient(sentinelAddrs []string, masterName string) {
+	routeMode = strings.ToLower(os.Getenv("SENTINEL_ROUTE_MODE"))
+	if routeMode == "" {
+		routeMode = "master"
+	}
+
+	switch routeMode {
+	case "random":
+		readClient = redis.NewFailoverClusterClient(&redis.FailoverOptions{
+			MasterName:    masterName,
+			SentinelAddrs: sentinelAddrs,
+			RouteRandomly: true,
+			DialTimeout:   10 * time.Second,
+			ReadTimeout:   3 * time.Second,
+			WriteTimeout:  3 * time.Second,
+		})
+	case "latency":
+		readClient = redis.NewFailoverClusterClient(&redis.FailoverOptions{
+			MasterName:     masterName,
+			SentinelAddrs:  sentinelAddrs,
+			RouteByLatency: true,
+			DialTimeout:    10 * time.Second,
+			ReadTimeout:    3 * time.Second,
+			WriteTimeout:   3 * time.Second,
+		})
+	default:
+		routeMode = "master"
+		readClient = sentinelClient
+	}
+}
+
+// selectReadClient picks the Cmdable a read handler should use: the request
+// can force strong consistency (master-only reads) regardless of the
+// configured route mode via ?consistency=strong. Returns the client and
+// whether it resolved to "master", for the served-by counters below.
+func selectReadClient(r *http.Request) (redis.Cmdable, bool) {
+	if r.URL.Query().Get("consistency") == "strong" || routeMode == "master" {
+		return sentinelClient, true
+	}
+	return readClient, false
+}
+
+// recordServedBy tallies whether an endpoint's read was sent to
+// sentinelClient ("master") or to the replica-routed readClient
+// ("replica"), so /stats can show whether replica routing is actually
+// fanning out reads. Which node readClient then picks is not recorded.
+func recordServedBy(endpoint string, master bool) {
+	servedByMu.Lock()
+	e, ok := servedByCount[endpoint]
+	servedByMu.Unlock()
+	if !ok {
+		return
+	}
+	if master {
+		atomic.AddInt64(&e.master, 1)
+	} else {
+		atomic.AddInt64(&e.replica, 1)
+	}
+}
+
+// servedBySnapshot returns a JSON-friendly copy of the served-by counters.
+func servedBySnapshot() map[string]map[string]int64 {
+	out := make(map[string]map[string]int64, len(servedByCount))
+	servedByMu.Lock()
+	defer servedByMu.Unlock()
+	for endpoint, e := range servedByCount {
+		out[endpoint] = map[string]int64{
+			"master":  atomic.LoadInt64(&e.master),
+			"replica": atomic.LoadInt64(&e.replica),
+		}
+	}
+	return out
+}
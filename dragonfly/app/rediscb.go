@@ -0,0 +1,217 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/elimelt/ha-redis/resilience"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	readBreakerCooldown  = time.Duration(envInt("READ_BREAKER_COOLDOWN_SECONDS", 10)) * time.Second
+	writeBreakerCooldown = time.Duration(envInt("WRITE_BREAKER_COOLDOWN_SECONDS", 10)) * time.Second
+
+	readBreaker  = resilience.NewBreaker(envInt("READ_BREAKER_THRESHOLD", 5), readBreakerCooldown)
+	writeBreaker = resilience.NewBreaker(envInt("WRITE_BREAKER_THRESHOLD", 5), writeBreakerCooldown)
+
+	readRetries  int64
+	writeRetries int64
+)
+
+var (
+	circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ha_redis_circuit_breaker_state",
+		Help: "Per-command-class circuit breaker state: 0=closed, 1=half_open, 2=open.",
+	}, []string{"class"})
+
+	retryTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ha_redis_retry_total",
+		Help: "Count of retried Redis operations, labeled by command class.",
+	}, []string{"class"})
+)
+
+// envInt reads an integer environment variable, falling back to def if
+// unset or unparsable.
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// breakerFor resolves which per-command-class breaker a
+// resilientOp/resilientOpT call should use: "read" covers the
+// get/exists/lrange/smembers/hgetall handlers and workload reads, "write"
+// covers set/incr/lpush/sadd/hset and workload writes, so a run of write
+// failures during a failover doesn't trip the breaker that reads depend on.
+func breakerFor(class string) *resilience.Breaker {
+	if class == "write" {
+		return writeBreaker
+	}
+	return readBreaker
+}
+
+func retryCounterFor(class string) *int64 {
+	if class == "write" {
+		return &writeRetries
+	}
+	return &readRetries
+}
+
+// reportBreakerState syncs circuitBreakerState with a breaker's current
+// state after every Allow/RecordSuccess/RecordFailure call.
+func reportBreakerState(class string, breaker *resilience.Breaker) {
+	state := 0.0
+	switch breaker.State() {
+	case resilience.StateHalfOpen:
+		state = 1
+	case resilience.StateOpen:
+		state = 2
+	}
+	circuitBreakerState.WithLabelValues(class).Set(state)
+}
+
+// countRetry tallies one retried attempt against class, for /stats and
+// /metrics.
+func countRetry(class string) {
+	atomic.AddInt64(retryCounterFor(class), 1)
+	retryTotal.WithLabelValues(class).Inc()
+}
+
+// resilientOp wraps a sentinelClient/readClient call with retry-with-backoff
+// and a per-command-class circuit breaker: transient errors are retried,
+// and once a class's breaker trips, calls short-circuit to
+// resilience.ErrCircuitOpen immediately instead of piling up against a
+// downstream that's mid-failover.
+func resilientOp(class, op string, fn func() error) error {
+	breaker := breakerFor(class)
+	if !breaker.Allow() {
+		reportBreakerState(class, breaker)
+		return resilience.ErrCircuitOpen
+	}
+
+	attempt := 0
+	err := resilience.Retry(resilience.DefaultRetryConfig, isRetryableOpErr, func() error {
+		if attempt > 0 {
+			countRetry(class)
+		}
+		attempt++
+		return timedOp(op, fn)
+	})
+
+	if err != nil && err != redis.Nil {
+		breaker.RecordFailure()
+		reportBreakerState(class, breaker)
+		return err
+	}
+
+	breaker.RecordSuccess()
+	reportBreakerState(class, breaker)
+	return err
+}
+
+// resilientOpT is resilientOp for calls that also return a value.
+func resilientOpT[T any](class, op string, fn func() (T, error)) (T, error) {
+	var zero T
+
+	breaker := breakerFor(class)
+	if !breaker.Allow() {
+		reportBreakerState(class, breaker)
+		return zero, resilience.ErrCircuitOpen
+	}
+
+	var result T
+	attempt := 0
+	err := resilience.Retry(resilience.DefaultRetryConfig, isRetryableOpErr, func() error {
+		if attempt > 0 {
+			countRetry(class)
+		}
+		attempt++
+		v, err := timedOpT(op, fn)
+		result = v
+		return err
+	})
+
+	if err != nil && err != redis.Nil {
+		breaker.RecordFailure()
+		reportBreakerState(class, breaker)
+		return zero, err
+	}
+
+	breaker.RecordSuccess()
+	reportBreakerState(class, breaker)
+	return result, err
+}
+
+// isRetryableOpErr reports whether err is the kind of transient failure a
+// retry can plausibly fix: connection-level timeouts/EOF, or a RESP error
+// indicating the server is mid-failover or temporarily unwritable. A
+// redis.Nil miss is never retryable.
+func isRetryableOpErr(err error) bool {
+	if err == nil || err == redis.Nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "LOADING") ||
+		strings.Contains(msg, "MASTERDOWN") ||
+		strings.Contains(msg, "READONLY")
+}
+
+// respondResilienceError reports a failed resilientOp/resilientOpT call: a
+// short-circuited breaker gets a 503 with a Retry-After header naming how
+// much of its cooldown is left, so clients can back off instead of
+// hammering a downstream that's mid-failover; everything else is a 500 as
+// before.
+func respondResilienceError(w http.ResponseWriter, err error, class string) {
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		retryAfter := breakerFor(class).RemainingCooldown().Seconds()
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter))))
+		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+// resilienceSnapshot is the /stats view of breaker state and retry counts,
+// per command class.
+func resilienceSnapshot() map[string]interface{} {
+	return map[string]interface{}{
+		"read": map[string]interface{}{
+			"breakerState": readBreaker.State(),
+			"retries":      atomic.LoadInt64(&readRetries),
+		},
+		"write": map[string]interface{}{
+			"breakerState": writeBreaker.State(),
+			"retries":      atomic.LoadInt64(&writeRetries),
+		},
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elimelt/ha-redis/histogram"
+	"github.com/redis/go-redis/v9"
+)
+
+// zipfianTheta is the skew parameter used for the zipfian/latest
+// distributions, matching YCSB's default.
+const zipfianTheta = 0.99
+
+// keyGenerator picks key indices in [0, n) according to some distribution.
+type keyGenerator interface {
+	Next() int
+}
+
+// uniformGenerator picks keys with equal probability.
+type uniformGenerator struct{ n int }
+
+func (g *uniformGenerator) Next() int { return rand.Intn(g.n) }
+
+// zipfianGenerator implements YCSB's rejection-based Zipfian generator:
+// precompute the zeta constants for n and 2, derive eta from them, then map
+// a uniform draw through the Zipfian CDF's inverse.
+type zipfianGenerator struct {
+	n     int
+	theta float64
+	zetaN float64
+	eta   float64
+}
+
+func newZipfianGenerator(n int, theta float64) *zipfianGenerator {
+	zetaN := zeta(n, theta)
+	zeta2 := zeta(2, theta)
+	eta := (1 - math.Pow(2.0/float64(n), 1-theta)) / (1 - zeta2/zetaN)
+	return &zipfianGenerator{n: n, theta: theta, zetaN: zetaN, eta: eta}
+}
+
+// zeta computes the generalized harmonic number sum_{i=1}^{n} i^-theta.
+func zeta(n int, theta float64) float64 {
+	var sum float64
+	for i := 1; i <= n; i++ {
+		sum += 1.0 / math.Pow(float64(i), theta)
+	}
+	return sum
+}
+
+func (g *zipfianGenerator) Next() int {
+	u := rand.Float64()
+	x := int(float64(g.n) * math.Pow(g.eta*u-g.eta+1, 1/(1-g.theta)))
+	if x < 0 {
+		x = 0
+	}
+	if x >= g.n {
+		x = g.n - 1
+	}
+	return x
+}
+
+// latestGenerator is Zipfian over distance from the last key of the
+// keyspace (index n-1) rather than over the raw key range, so the hot set
+// sits at the tail of the keyspace instead of its head. It does not track
+// which key was actually written most recently.
+type latestGenerator struct {
+	zipf *zipfianGenerator
+	n    int
+}
+
+func (g *latestGenerator) Next() int {
+	return g.n - 1 - g.zipf.Next()
+}
+
+// hotspotGenerator sends hotOpFraction of requests to a hotFraction-sized
+// slice of the keyspace, and the rest uniformly across what's left.
+type hotspotGenerator struct {
+	n             int
+	hotFraction   float64
+	hotOpFraction float64
+}
+
+func (g *hotspotGenerator) Next() int {
+	if g.n <= 1 {
+		return 0
+	}
+
+	hotN := int(float64(g.n) * g.hotFraction)
+	if hotN < 1 {
+		hotN = 1
+	}
+	if hotN >= g.n {
+		hotN = g.n - 1
+	}
+	if rand.Float64() < g.hotOpFraction {
+		return rand.Intn(hotN)
+	}
+	return hotN + rand.Intn(g.n-hotN)
+}
+
+// newKeyGenerator builds the keyGenerator for a named distribution,
+// defaulting to uniform for anything unrecognized.
+func newKeyGenerator(distribution string, n int) keyGenerator {
+	switch distribution {
+	case "zipfian":
+		return newZipfianGenerator(n, zipfianTheta)
+	case "latest":
+		return &latestGenerator{zipf: newZipfianGenerator(n, zipfianTheta), n: n}
+	case "hotspot":
+		return &hotspotGenerator{n: n, hotFraction: 0.2, hotOpFraction: 0.8}
+	default:
+		return &uniformGenerator{n: n}
+	}
+}
+
+// buildWorkloadOpMix resolves the op/weight list for a named YCSB profile,
+// or a caller-supplied opMix when profile is "custom".
+func buildWorkloadOpMix(profile string, opMix map[string]int) ([]string, []int) {
+	switch profile {
+	case "ycsb-b":
+		return []string{"read", "update"}, []int{95, 5}
+	case "ycsb-c":
+		return []string{"read"}, []int{100}
+	case "ycsb-f":
+		return []string{"rmw"}, []int{100}
+	case "custom":
+		ops := make([]string, 0, len(opMix))
+		weights := make([]int, 0, len(opMix))
+		for op, weight := range opMix {
+			if weight <= 0 {
+				continue
+			}
+			ops = append(ops, op)
+			weights = append(weights, weight)
+		}
+		if len(ops) > 0 {
+			return ops, weights
+		}
+		fallthrough
+	default: // "ycsb-a" and anything unrecognized
+		return []string{"read", "update"}, []int{50, 50}
+	}
+}
+
+// workloadConfig parameterizes one /load run.
+type workloadConfig struct {
+	profile      string
+	distribution string
+	concurrency  int
+	duration     time.Duration
+	keyspaceSize int
+	valueSize    int
+	ops          []string
+	weights      []int
+}
+
+// WorkloadResult is the JSON-serializable outcome of a workload run.
+type WorkloadResult struct {
+	Profile             string             `json:"profile"`
+	Distribution        string             `json:"distribution"`
+	DurationSecs        float64            `json:"durationSeconds"`
+	Completed           int64              `json:"completed"`
+	Successful          int64              `json:"successful"`
+	Failed              int64              `json:"failed"`
+	Reads               int64              `json:"reads"`
+	Writes              int64              `json:"writes"`
+	ThroughputPerSecond []int64            `json:"throughputPerSecond"`
+	Latency             histogram.Snapshot `json:"latency"`
+}
+
+// runWorkload spawns cfg.concurrency workers against sentinelClient for
+// cfg.duration, each drawing an op from cfg.ops/weights and a key from the
+// configured distribution. Per-second throughput is sampled by a ticker
+// goroutine rather than truly streamed to the client, since this endpoint's
+// response is a single JSON object like the rest of the API. /stats is only
+// updated with a run's counts once the run has finished.
+func runWorkload(cfg workloadConfig) WorkloadResult {
+	runCtx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	keyGen := newKeyGenerator(cfg.distribution, cfg.keyspaceSize)
+	var keyGenMu sync.Mutex
+
+	hist := histogram.New()
+	var completed, successful, failed, reads, writes int64
+
+	var throughputMu sync.Mutex
+	var throughput []int64
+	var lastCompleted int64
+
+	tickerDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				cur := atomic.LoadInt64(&completed)
+				throughputMu.Lock()
+				throughput = append(throughput, cur-lastCompleted)
+				throughputMu.Unlock()
+				lastCompleted = cur
+			case <-runCtx.Done():
+				close(tickerDone)
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-runCtx.Done():
+					return
+				default:
+				}
+
+				keyGenMu.Lock()
+				key := keyGen.Next()
+				keyGenMu.Unlock()
+
+				op := pickWeighted(cfg.ops, cfg.weights)
+
+				opStart := time.Now()
+				isWrite, err := executeWorkloadOp(op, key, cfg.valueSize)
+				hist.Record(time.Since(opStart))
+
+				atomic.AddInt64(&completed, 1)
+				if isWrite {
+					atomic.AddInt64(&writes, 1)
+				} else {
+					atomic.AddInt64(&reads, 1)
+				}
+				if err != nil {
+					atomic.AddInt64(&failed, 1)
+				} else {
+					atomic.AddInt64(&successful, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	<-tickerDone
+
+	return WorkloadResult{
+		Profile:             cfg.profile,
+		Distribution:        cfg.distribution,
+		DurationSecs:        time.Since(start).Seconds(),
+		Completed:           atomic.LoadInt64(&completed),
+		Successful:          atomic.LoadInt64(&successful),
+		Failed:              atomic.LoadInt64(&failed),
+		Reads:               atomic.LoadInt64(&reads),
+		Writes:              atomic.LoadInt64(&writes),
+		ThroughputPerSecond: throughput,
+		Latency:             hist.Snapshot(),
+	}
+}
+
+// executeWorkloadOp runs one logical workload operation against
+// sentinelClient, through the same retry/circuit-breaker wrapper as the
+// regular handlers, and reports whether it was a write.
+func executeWorkloadOp(op string, key, valueSize int) (bool, error) {
+	k := fmt.Sprintf("ycsb:%d", key)
+
+	switch op {
+	case "read":
+		_, err := resilientOpT("read", "workload_read", func() (string, error) {
+			return sentinelClient.Get(ctx, k).Result()
+		})
+		if err == redis.Nil {
+			err = nil
+		}
+		return false, err
+	case "update", "insert":
+		err := resilientOp("write", "workload_write", func() error {
+			return sentinelClient.Set(ctx, k, generateRandomString(valueSize), 0).Err()
+		})
+		return true, err
+	case "rmw":
+		_, err := resilientOpT("read", "workload_rmw_read", func() (string, error) {
+			return sentinelClient.Get(ctx, k).Result()
+		})
+		if err != nil && err != redis.Nil {
+			return true, err
+		}
+		return true, resilientOp("write", "workload_rmw_write", func() error {
+			return sentinelClient.Set(ctx, k, generateRandomString(valueSize), 0).Err()
+		})
+	default:
+		return false, fmt.Errorf("unknown workload op %q", op)
+	}
+}
+
+// pickWeighted returns one op chosen proportionally to its weight.
+func pickWeighted(ops []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return ops[rand.Intn(len(ops))]
+	}
+
+	r := rand.Intn(total)
+	for i, w := range weights {
+		if r < w {
+			return ops[i]
+		}
+		r -= w
+	}
+	return ops[len(ops)-1]
+}
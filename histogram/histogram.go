@@ -0,0 +1,100 @@
+// Package histogram implements a small concurrency-safe latency histogram,
+// loosely modeled on HdrHistogram: samples are bucketed by power-of-two
+// nanosecond ranges, giving bounded relative error at any scale from
+// microseconds to tens of seconds without storing every sample.
+package histogram
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+const bucketCount = 64 // one bucket per bit position of a uint64 nanosecond duration
+
+// Histogram accumulates latency samples and reports percentiles, safe for
+// concurrent use by multiple recording goroutines.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets [bucketCount]int64
+	count   int64
+	sum     int64
+	min     int64
+	max     int64
+}
+
+// New returns an empty Histogram.
+func New() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+// Record adds one latency sample.
+func (h *Histogram) Record(d time.Duration) {
+	ns := int64(d)
+	if ns < 1 {
+		ns = 1
+	}
+
+	bucket := bits.Len64(uint64(ns)) - 1
+
+	h.mu.Lock()
+	h.buckets[bucket]++
+	h.count++
+	h.sum += ns
+	if ns < h.min {
+		h.min = ns
+	}
+	if ns > h.max {
+		h.max = ns
+	}
+	h.mu.Unlock()
+}
+
+// Snapshot is a point-in-time summary of recorded latencies.
+type Snapshot struct {
+	Count int64         `json:"count"`
+	Min   time.Duration `json:"min"`
+	Max   time.Duration `json:"max"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	P999  time.Duration `json:"p999"`
+}
+
+// Snapshot returns the current count, min/max/mean, and percentiles.
+func (h *Histogram) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		return Snapshot{}
+	}
+
+	return Snapshot{
+		Count: h.count,
+		Min:   time.Duration(h.min),
+		Max:   time.Duration(h.max),
+		Mean:  time.Duration(h.sum / h.count),
+		P50:   h.percentileLocked(0.50),
+		P95:   h.percentileLocked(0.95),
+		P99:   h.percentileLocked(0.99),
+		P999:  h.percentileLocked(0.999),
+	}
+}
+
+// percentileLocked returns the bucket upper bound containing the p-th
+// percentile sample. Callers must hold h.mu.
+func (h *Histogram) percentileLocked(p float64) time.Duration {
+	target := int64(math.Ceil(p * float64(h.count)))
+
+	var cum int64
+	for i, c := range h.buckets {
+		cum += c
+		if cum >= target {
+			return time.Duration(int64(1) << uint(i+1))
+		}
+	}
+	return time.Duration(h.max)
+}
@@ -0,0 +1,129 @@
+// Package resilience provides small, dependency-free building blocks for
+// calling a flaky downstream (retry with backoff, a circuit breaker, and a
+// bounded write-ahead buffer for replaying dropped writes) so each HTTP app
+// in this repo can wrap its write path the same way instead of reinventing
+// it per handler.
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Breaker states, as surfaced to operators.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// ErrCircuitOpen is returned by callers that check Allow() and find the
+// breaker short-circuiting calls.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Breaker is a per-client circuit breaker: it opens after maxFailures
+// consecutive failures, short-circuits calls for cooldown, then allows a
+// single half-open probe to decide whether to close again.
+type Breaker struct {
+	mu sync.Mutex
+
+	maxFailures int
+	cooldown    time.Duration
+
+	state            string
+	consecutiveFails int
+	openedAt         time.Time
+	probing          bool
+}
+
+// NewBreaker returns a closed Breaker that opens after maxFailures
+// consecutive failures and stays open for cooldown before probing again.
+func NewBreaker(maxFailures int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		state:       StateClosed,
+	}
+}
+
+// Allow reports whether a call should proceed. In the open state it
+// transitions to half-open (and allows exactly one probing call through)
+// once cooldown has elapsed.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordSuccess closes the breaker and resets the failure count.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.consecutiveFails = 0
+	b.state = StateClosed
+	b.probing = false
+}
+
+// RecordFailure counts a failure, opening the breaker once maxFailures
+// consecutive failures have been observed (or immediately, if the failure
+// was a half-open probe).
+func (b *Breaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.probing = false
+		return
+	}
+
+	b.consecutiveFails++
+	if b.consecutiveFails >= b.maxFailures {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the breaker's current state for reporting.
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// RemainingCooldown returns how much longer an open breaker will
+// short-circuit calls for, so a caller can tell clients how long to back
+// off. It's zero once the breaker isn't open or its cooldown has elapsed.
+func (b *Breaker) RemainingCooldown() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateOpen {
+		return 0
+	}
+	remaining := b.cooldown - time.Since(b.openedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
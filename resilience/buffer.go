@@ -0,0 +1,93 @@
+package resilience
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WriteAheadBuffer is a bounded FIFO ring of failed mutations, captured so
+// they can be replayed in order once their breaker closes again. Pushing
+// past capacity drops the oldest entry, trading durability of very old
+// writes for a bounded memory footprint.
+type WriteAheadBuffer struct {
+	mu        sync.Mutex
+	items     []func() error
+	capacity  int
+	replayed  int64
+	dropped   int64
+	replaying int32
+}
+
+// NewWriteAheadBuffer returns an empty buffer bounded at capacity entries.
+func NewWriteAheadBuffer(capacity int) *WriteAheadBuffer {
+	return &WriteAheadBuffer{capacity: capacity}
+}
+
+// Push appends op, dropping the oldest buffered op if the buffer is full.
+func (b *WriteAheadBuffer) Push(op func() error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.capacity <= 0 {
+		return
+	}
+	if len(b.items) >= b.capacity {
+		b.items = b.items[1:]
+		b.dropped++
+	}
+	b.items = append(b.items, op)
+}
+
+// Depth returns the number of buffered, not-yet-replayed writes.
+func (b *WriteAheadBuffer) Depth() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+// Replayed returns the total count of writes successfully replayed so far.
+func (b *WriteAheadBuffer) Replayed() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.replayed
+}
+
+// Dropped returns the total count of writes evicted before they could be
+// replayed, because the buffer was full.
+func (b *WriteAheadBuffer) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// TriggerReplay starts a Replay in the background, unless one is already
+// running, so a caller on the request path never blocks on draining the
+// whole backlog itself.
+func (b *WriteAheadBuffer) TriggerReplay() {
+	if !atomic.CompareAndSwapInt32(&b.replaying, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&b.replaying, 0)
+		b.Replay()
+	}()
+}
+
+// Replay executes every buffered op in order, stopping at the first
+// failure so writes aren't replayed out of order. Successfully replayed ops
+// are removed; the rest remain buffered for the next attempt. Exported so
+// callers that want to replay synchronously (e.g. at shutdown) still can;
+// TriggerReplay is the right choice from the request path.
+func (b *WriteAheadBuffer) Replay() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := 0
+	for ; i < len(b.items); i++ {
+		if err := b.items[i](); err != nil {
+			break
+		}
+		b.replayed++
+	}
+	b.items = b.items[i:]
+}
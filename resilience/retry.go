@@ -0,0 +1,51 @@
+package resilience
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryConfig bounds a Retry call's attempts and backoff curve.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryConfig matches the repo's standard write-path retry policy:
+// up to 3 attempts (the first try plus 2 retries), exponential backoff from
+// 10ms capped at 1s, with jitter.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 3,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    1 * time.Second,
+}
+
+// Retry calls fn until it succeeds, isRetryable(err) returns false, or
+// cfg.MaxAttempts is reached, sleeping an exponentially increasing,
+// jittered delay between attempts. It returns fn's last error.
+func Retry(cfg RetryConfig, isRetryable func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !isRetryable(err) || attempt == cfg.MaxAttempts-1 {
+			return err
+		}
+		time.Sleep(backoff(cfg, attempt))
+	}
+	return err
+}
+
+// backoff computes the delay before the given attempt (0-indexed): base *
+// 2^attempt, capped at MaxDelay, with up to 50% jitter to avoid thundering
+// herds of retrying clients.
+func backoff(cfg RetryConfig, attempt int) time.Duration {
+	d := cfg.BaseDelay << uint(attempt)
+	if d > cfg.MaxDelay || d <= 0 {
+		d = cfg.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	return d/2 + jitter
+}
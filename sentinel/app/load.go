@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elimelt/ha-redis/histogram"
+	"github.com/redis/go-redis/v9"
+)
+
+var loadWriteOps = map[string]bool{
+	"set": true, "incr": true, "lpush": true, "sadd": true, "hset": true,
+}
+
+// loadConfig parameterizes one /load benchmark run.
+type loadConfig struct {
+	operations   int
+	concurrency  int
+	pipelineSize int
+	keyspaceSize int
+	valueSize    int
+	duration     time.Duration
+	ops          []string
+	weights      []int
+}
+
+// LoadResult is the JSON-serializable outcome of a benchmark run.
+type LoadResult struct {
+	Requested    int                `json:"requested"`
+	Completed    int64              `json:"completed"`
+	Successful   int64              `json:"successful"`
+	Failed       int64              `json:"failed"`
+	Reads        int64              `json:"reads"`
+	Writes       int64              `json:"writes"`
+	DurationSecs float64            `json:"durationSeconds"`
+	OpsPerSec    float64            `json:"opsPerSec"`
+	ErrorsByOp   map[string]int64   `json:"errorsByOp"`
+	Latency      histogram.Snapshot `json:"latency"`
+}
+
+// buildOpMix resolves the set of operations and their relative selection
+// weights for a benchmark run. An explicit opMix always wins; otherwise the
+// standard five read and five write ops are weighted evenly within their
+// side of readWriteRatio, preserving the previous uniform-random behavior's
+// read/write split.
+func buildOpMix(opMix map[string]int, readWriteRatio int) ([]string, []int) {
+	if len(opMix) > 0 {
+		ops := make([]string, 0, len(opMix))
+		weights := make([]int, 0, len(opMix))
+		for op, weight := range opMix {
+			if weight <= 0 {
+				continue
+			}
+			ops = append(ops, op)
+			weights = append(weights, weight)
+		}
+		if len(ops) > 0 {
+			return ops, weights
+		}
+	}
+
+	readOps := []string{"get", "exists", "lrange", "smembers", "hgetall"}
+	writeOps := []string{"set", "incr", "lpush", "sadd", "hset"}
+
+	ops := make([]string, 0, len(readOps)+len(writeOps))
+	weights := make([]int, 0, len(readOps)+len(writeOps))
+	for _, op := range readOps {
+		ops = append(ops, op)
+		weights = append(weights, readWriteRatio)
+	}
+	for _, op := range writeOps {
+		ops = append(ops, op)
+		weights = append(weights, 100-readWriteRatio)
+	}
+	return ops, weights
+}
+
+// pickWeighted returns one op chosen proportionally to its weight.
+func pickWeighted(ops []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 {
+		return ops[rand.Intn(len(ops))]
+	}
+
+	r := rand.Intn(total)
+	for i, w := range weights {
+		if r < w {
+			return ops[i]
+		}
+		r -= w
+	}
+	return ops[len(ops)-1]
+}
+
+// runLoadBenchmark drives cfg.concurrency workers, each repeatedly building
+// a cfg.pipelineSize batch of randomly-mixed operations and executing its
+// writes as one pipelined round trip against the master and its reads as
+// one against a slave, until either cfg.operations have been
+// issued or cfg.duration has elapsed (duration wins if both are set).
+func runLoadBenchmark(cfg loadConfig) LoadResult {
+	var remaining int64 = int64(cfg.operations)
+	var completed, successful, failed, reads, writes int64
+
+	var errMu sync.Mutex
+	errorsByOp := make(map[string]int64)
+
+	hist := histogram.New()
+
+	var deadline time.Time
+	if cfg.duration > 0 {
+		deadline = time.Now().Add(cfg.duration)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				batchSize := cfg.pipelineSize
+				if deadline.IsZero() {
+					left := atomic.AddInt64(&remaining, -int64(batchSize))
+					if left+int64(batchSize) <= 0 {
+						return
+					}
+					if left < 0 {
+						batchSize += int(left)
+					}
+				} else if time.Now().After(deadline) {
+					return
+				}
+
+				batch := make([]string, batchSize)
+				for j := range batch {
+					batch[j] = pickWeighted(cfg.ops, cfg.weights)
+				}
+
+				var writeCmds, readCmds []string
+				for _, op := range batch {
+					if loadWriteOps[op] {
+						writeCmds = append(writeCmds, op)
+					} else {
+						readCmds = append(readCmds, op)
+					}
+				}
+
+				if len(writeCmds) > 0 {
+					execLoadBatch(currentMaster(), writeCmds, cfg.keyspaceSize, cfg.valueSize, hist,
+						&completed, &successful, &failed, &writes, &errMu, errorsByOp)
+				}
+				if len(readCmds) > 0 {
+					execLoadBatch(currentSlave(), readCmds, cfg.keyspaceSize, cfg.valueSize, hist,
+						&completed, &successful, &failed, &reads, &errMu, errorsByOp)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start).Seconds()
+	opsPerSec := 0.0
+	if elapsed > 0 {
+		opsPerSec = float64(atomic.LoadInt64(&completed)) / elapsed
+	}
+
+	return LoadResult{
+		Requested:    cfg.operations,
+		Completed:    atomic.LoadInt64(&completed),
+		Successful:   atomic.LoadInt64(&successful),
+		Failed:       atomic.LoadInt64(&failed),
+		Reads:        atomic.LoadInt64(&reads),
+		Writes:       atomic.LoadInt64(&writes),
+		DurationSecs: elapsed,
+		OpsPerSec:    opsPerSec,
+		ErrorsByOp:   errorsByOp,
+		Latency:      hist.Snapshot(),
+	}
+}
+
+// execLoadBatch queues cmdOps onto a single pipeline against client, executes
+// it as one round trip, and records the results. Pipelined commands don't
+// have individually observable network latency, so the batch's round-trip
+// time is divided evenly across its commands as a per-op estimate.
+func execLoadBatch(
+	client *redis.Client,
+	cmdOps []string,
+	keyspaceSize, valueSize int,
+	hist *histogram.Histogram,
+	completed, successful, failed, dirCounter *int64,
+	errMu *sync.Mutex,
+	errorsByOp map[string]int64,
+) {
+	pipe := client.Pipeline()
+	cmders := make([]redis.Cmder, len(cmdOps))
+	for i, op := range cmdOps {
+		cmders[i] = queueLoadOp(pipe, op, keyspaceSize, valueSize)
+	}
+
+	start := time.Now()
+	pipe.Exec(ctx)
+	perOpLatency := time.Since(start) / time.Duration(len(cmdOps))
+
+	for i, cmder := range cmders {
+		atomic.AddInt64(completed, 1)
+		atomic.AddInt64(dirCounter, 1)
+		hist.Record(perOpLatency)
+
+		if err := cmder.Err(); err != nil && err != redis.Nil {
+			atomic.AddInt64(failed, 1)
+			errMu.Lock()
+			errorsByOp[cmdOps[i]]++
+			errMu.Unlock()
+		} else {
+			atomic.AddInt64(successful, 1)
+		}
+	}
+}
+
+// queueLoadOp appends one randomly-keyed command of the given op type to
+// pipe and returns its Cmder for later result inspection.
+func queueLoadOp(pipe redis.Pipeliner, op string, keyspaceSize, valueSize int) redis.Cmder {
+	switch op {
+	case "set":
+		return pipe.Set(ctx, loadKey(keyspaceSize), generateRandomString(valueSize), 300*time.Second)
+	case "incr":
+		return pipe.Incr(ctx, fmt.Sprintf("counter:%d", getRandomInt(1, 100)))
+	case "lpush":
+		return pipe.LPush(ctx, fmt.Sprintf("list:%d", getRandomInt(1, 50)), generateRandomString(valueSize))
+	case "sadd":
+		return pipe.SAdd(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50)), generateRandomString(valueSize))
+	case "hset":
+		return pipe.HSet(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50)), generateRandomString(10), generateRandomString(valueSize))
+	case "get":
+		return pipe.Get(ctx, loadKey(keyspaceSize))
+	case "exists":
+		return pipe.Exists(ctx, loadKey(keyspaceSize))
+	case "lrange":
+		return pipe.LRange(ctx, fmt.Sprintf("list:%d", getRandomInt(1, 50)), 0, 10)
+	case "smembers":
+		return pipe.SMembers(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50)))
+	case "hgetall":
+		return pipe.HGetAll(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50)))
+	default:
+		// Not a real command, so don't queue anything onto pipe for it: just
+		// hand back a Cmder that's already failed, so execLoadBatch reports
+		// the typo instead of a disguised no-op success.
+		errCmd := redis.NewStatusCmd(ctx)
+		errCmd.SetErr(fmt.Errorf("unknown op %q", op))
+		return errCmd
+	}
+}
+
+func loadKey(keyspaceSize int) string {
+	return fmt.Sprintf("key:%d", getRandomInt(1, keyspaceSize))
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -10,21 +11,56 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/elimelt/ha-redis/resilience"
+	"github.com/elimelt/ha-redis/sentinel/cache"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 )
 
+// cacheInvalidateChannel is the pub/sub channel writers publish to, and every
+// node subscribes to, so local LRU entries stay coherent with Redis.
+const cacheInvalidateChannel = "cache:invalidate"
+
 var (
+	// masterClient and slaveClient are used directly only in static (non-Sentinel)
+	// mode. Once Sentinel is configured, reads/writes go through currentMaster()/
+	// currentSlave(), which resolve the live topology below.
 	masterClient *redis.Client
 	slaveClient  *redis.Client
 	ctx          = context.Background()
 	stats        Stats
+
+	sentinelMode bool
+
+	// masterPtr holds the Sentinel-backed FailoverClient. It is stored once at
+	// startup and not swapped on Sentinel events; the FailoverClient follows
+	// the master through Sentinel itself. slavePool and topology hold the live
+	// topology as reported by Sentinel and are swapped atomically whenever a
+	// +switch-master/+slave/+sdown/+odown event fires, so in-flight handlers
+	// never observe a half-updated pointer.
+	masterPtr  atomic.Value // *redis.Client
+	slavePool  atomic.Value // []*redis.Client
+	topology   atomic.Value // Topology
+	slaveNextI int64        // round-robin cursor into slavePool
+
+	localCache *cache.Cache
+	cacheTTL   time.Duration
 )
 
+// Topology is a snapshot of the Sentinel-reported cluster state, surfaced at
+// /health so operators can confirm HA failover actually happened.
+type Topology struct {
+	MasterAddr   string    `json:"masterAddr"`
+	SlaveAddrs   []string  `json:"slaveAddrs"`
+	LastFailover time.Time `json:"lastFailover"`
+}
+
 type Stats struct {
 	TotalRequests      int64 `json:"totalRequests"`
 	SuccessfulRequests int64 `json:"successfulRequests"`
@@ -32,6 +68,11 @@ type Stats struct {
 	Reads              int64 `json:"reads"`
 	Writes             int64 `json:"writes"`
 	StartTime          int64 `json:"startTime"`
+
+	CacheLocalHits   int64 `json:"cacheLocalHits"`
+	CacheLocalMisses int64 `json:"cacheLocalMisses"`
+	CacheRedisHits   int64 `json:"cacheRedisHits"`
+	CacheRedisMisses int64 `json:"cacheRedisMisses"`
 }
 
 func init() {
@@ -64,37 +105,62 @@ func main() {
 		slavePort = "6379"
 	}
 
-	log.Printf("Connecting to Redis master: %s:%s", masterHost, masterPort)
-	log.Printf("Connecting to Redis slave: %s:%s", slaveHost, slavePort)
-
-	// Create Redis clients
-	masterClient = redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%s", masterHost, masterPort),
-		DialTimeout:  10 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-	})
+	sentinelAddrsEnv := os.Getenv("REDIS_SENTINEL_ADDRS")
+	masterName := os.Getenv("REDIS_MASTER_NAME")
 
-	slaveClient = redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%s", slaveHost, slavePort),
-		DialTimeout:  10 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-	})
+	if sentinelAddrsEnv != "" && masterName != "" {
+		sentinelAddrs := splitAndTrim(sentinelAddrsEnv)
+		log.Printf("Connecting to Redis via Sentinel: addrs=%v master=%s", sentinelAddrs, masterName)
 
-	// Test connections
-	if err := masterClient.Ping(ctx).Err(); err != nil {
-		log.Printf("Failed to connect to Redis master: %v", err)
+		sentinelMode = true
+		setupSentinelTopology(sentinelAddrs, masterName)
 	} else {
-		log.Println("Connected to Redis master")
+		log.Printf("Connecting to Redis master: %s:%s", masterHost, masterPort)
+		log.Printf("Connecting to Redis slave: %s:%s", slaveHost, slavePort)
+
+		// Create Redis clients
+		masterClient = redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%s", masterHost, masterPort),
+			DialTimeout:  10 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+		})
+
+		slaveClient = redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%s", slaveHost, slavePort),
+			DialTimeout:  10 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+		})
+
+		// Test connections
+		if err := masterClient.Ping(ctx).Err(); err != nil {
+			log.Printf("Failed to connect to Redis master: %v", err)
+		} else {
+			log.Println("Connected to Redis master")
+		}
+
+		if err := slaveClient.Ping(ctx).Err(); err != nil {
+			log.Printf("Failed to connect to Redis slave: %v", err)
+		} else {
+			log.Println("Connected to Redis slave")
+		}
 	}
 
-	if err := slaveClient.Ping(ctx).Err(); err != nil {
-		log.Printf("Failed to connect to Redis slave: %v", err)
-	} else {
-		log.Println("Connected to Redis slave")
+	maxEntries := envInt("CACHE_MAX_ENTRIES", 10000)
+	maxBytes := envInt("CACHE_MAX_BYTES", 0)
+	cacheTTL = time.Duration(envInt("CACHE_TTL_SECONDS", 30)) * time.Second
+	localCache = cache.New(maxEntries, maxBytes)
+
+	go subscribeCacheInvalidations()
+
+	if os.Getenv("ENABLE_CLIENT_TRACKING") == "true" {
+		bcast := os.Getenv("CLIENT_TRACKING_BCAST") == "true"
+		go watchClientTracking(bcast)
 	}
 
+	go watchPoolAndReplicationMetrics(10 * time.Second)
+
 	// Setup router
 	r := mux.NewRouter()
 	r.Use(loggingMiddleware)
@@ -102,6 +168,7 @@ func main() {
 	// API Routes
 	r.HandleFunc("/health", healthHandler).Methods("GET")
 	r.HandleFunc("/stats", statsHandler).Methods("GET")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 	r.HandleFunc("/stats/reset", resetStatsHandler).Methods("POST")
 	r.HandleFunc("/set", setHandler).Methods("POST")
 	r.HandleFunc("/incr", incrHandler).Methods("POST")
@@ -131,6 +198,7 @@ func main() {
 		log.Println("Available endpoints:")
 		log.Println("  GET  /health - Health check")
 		log.Println("  GET  /stats - View statistics")
+		log.Println("  GET  /metrics - Prometheus metrics")
 		log.Println("  POST /stats/reset - Reset statistics")
 		log.Println("  POST /set - SET operation")
 		log.Println("  POST /incr - INCR operation")
@@ -162,12 +230,331 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
-	masterClient.Close()
-	slaveClient.Close()
+	if sentinelMode {
+		if c, ok := masterPtr.Load().(*redis.Client); ok && c != nil {
+			c.Close()
+		}
+		for _, c := range currentSlavePool() {
+			c.Close()
+		}
+	} else {
+		masterClient.Close()
+		slaveClient.Close()
+	}
 
 	log.Println("Server exited")
 }
 
+// splitAndTrim splits a comma-separated address list and trims whitespace
+// from each entry, e.g. "sentinel-1:26379, sentinel-2:26379".
+func splitAndTrim(s string) []string {
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// setupSentinelTopology resolves the initial master/slave addresses from
+// Sentinel, builds the corresponding clients, and starts a background
+// watcher that keeps the topology current as Sentinel reports changes.
+func setupSentinelTopology(sentinelAddrs []string, masterName string) {
+	masterClient := redis.NewFailoverClient(&redis.FailoverOptions{
+		MasterName:    masterName,
+		SentinelAddrs: sentinelAddrs,
+		DialTimeout:   10 * time.Second,
+		ReadTimeout:   3 * time.Second,
+		WriteTimeout:  3 * time.Second,
+	})
+	masterPtr.Store(masterClient)
+
+	if err := masterClient.Ping(ctx).Err(); err != nil {
+		log.Printf("Failed to connect to Redis master via Sentinel: %v", err)
+	} else {
+		log.Println("Connected to Redis master via Sentinel")
+	}
+
+	refreshTopology(sentinelAddrs, masterName)
+
+	go watchSentinelEvents(sentinelAddrs, masterName)
+}
+
+// refreshTopology queries the first reachable sentinel for the current
+// master address and slave list, rebuilds the slave read pool, and
+// atomically publishes the new Topology snapshot.
+func refreshTopology(sentinelAddrs []string, masterName string) {
+	for _, addr := range sentinelAddrs {
+		sentinel := redis.NewSentinelClient(&redis.Options{
+			Addr:        addr,
+			DialTimeout: 5 * time.Second,
+			ReadTimeout: 5 * time.Second,
+		})
+
+		masterAddr, err := sentinel.GetMasterAddrByName(ctx, masterName).Result()
+		if err != nil {
+			sentinel.Close()
+			continue
+		}
+
+		slaves, err := sentinel.Replicas(ctx, masterName).Result()
+		sentinel.Close()
+		if err != nil {
+			log.Printf("Failed to fetch Sentinel slaves: %v", err)
+			slaves = nil
+		}
+
+		pool := make([]*redis.Client, 0, len(slaves))
+		addrs := make([]string, 0, len(slaves))
+		for _, s := range slaves {
+			ip, ok := s["ip"]
+			if !ok {
+				continue
+			}
+			port := s["port"]
+			if flags, ok := s["flags"]; ok && strings.Contains(flags, "s_down") {
+				continue
+			}
+			slaveAddr := fmt.Sprintf("%s:%s", ip, port)
+			pool = append(pool, redis.NewClient(&redis.Options{
+				Addr:         slaveAddr,
+				DialTimeout:  10 * time.Second,
+				ReadTimeout:  3 * time.Second,
+				WriteTimeout: 3 * time.Second,
+			}))
+			addrs = append(addrs, slaveAddr)
+		}
+
+		if old, ok := slavePool.Load().([]*redis.Client); ok {
+			for _, c := range old {
+				c.Close()
+			}
+		}
+		slavePool.Store(pool)
+
+		prev, _ := topology.Load().(Topology)
+		lastFailover := prev.LastFailover
+		if prev.MasterAddr != "" && prev.MasterAddr != fmt.Sprintf("%s:%s", masterAddr[0], masterAddr[1]) {
+			lastFailover = time.Now()
+		}
+		topology.Store(Topology{
+			MasterAddr:   fmt.Sprintf("%s:%s", masterAddr[0], masterAddr[1]),
+			SlaveAddrs:   addrs,
+			LastFailover: lastFailover,
+		})
+		return
+	}
+
+	log.Println("Failed to refresh Sentinel topology: no reachable sentinels")
+}
+
+// watchSentinelEvents subscribes to the Sentinel pub/sub channels that signal
+// topology changes and re-resolves master/slave addresses whenever one fires.
+func watchSentinelEvents(sentinelAddrs []string, masterName string) {
+	channels := []string{"+switch-master", "+sdown", "+odown", "+slave"}
+
+	for _, addr := range sentinelAddrs {
+		go func(addr string) {
+			sentinel := redis.NewSentinelClient(&redis.Options{Addr: addr})
+			defer sentinel.Close()
+
+			sub := sentinel.Subscribe(ctx, channels...)
+			defer sub.Close()
+
+			for msg := range sub.Channel() {
+				log.Printf("Sentinel event from %s: %s %s", addr, msg.Channel, msg.Payload)
+				refreshTopology(sentinelAddrs, masterName)
+			}
+		}(addr)
+	}
+}
+
+// currentMaster returns the client that should serve writes: the live
+// Sentinel-resolved master in Sentinel mode, or the static masterClient
+// otherwise.
+func currentMaster() *redis.Client {
+	if sentinelMode {
+		if c, ok := masterPtr.Load().(*redis.Client); ok {
+			return c
+		}
+	}
+	return masterClient
+}
+
+// currentSlave returns a client for reads, round-robining across the
+// Sentinel-resolved slave pool in Sentinel mode, or the static slaveClient
+// otherwise. Falls back to currentMaster() if no slaves are available.
+func currentSlave() *redis.Client {
+	if sentinelMode {
+		pool := currentSlavePool()
+		if len(pool) == 0 {
+			return currentMaster()
+		}
+		i := atomic.AddInt64(&slaveNextI, 1)
+		return pool[int(i)%len(pool)]
+	}
+	return slaveClient
+}
+
+func currentSlavePool() []*redis.Client {
+	pool, _ := slavePool.Load().([]*redis.Client)
+	return pool
+}
+
+// trackedConn holds the single dedicated connection CLIENT TRACKING was
+// enabled on, while runClientTracking has it up. CLIENT TRACKING only
+// tracks keys read on the connection it was enabled on, so every read that
+// wants server-pushed invalidations has to go through this same
+// connection rather than the round-robined currentSlave() pool.
+var trackedConn atomic.Value // *redis.Conn
+
+// trackedReadClient returns the pinned CLIENT TRACKING connection while
+// it's up, falling back to the regular currentSlave() pool otherwise.
+func trackedReadClient() redis.Cmdable {
+	if conn, ok := trackedConn.Load().(*redis.Conn); ok && conn != nil {
+		return conn
+	}
+	return currentSlave()
+}
+
+// envInt reads an integer environment variable, falling back to def if unset
+// or unparsable.
+func envInt(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// subscribeCacheInvalidations listens on cacheInvalidateChannel and evicts
+// the affected key from every cached operation's entry in the local LRU.
+// Every node runs this, so a write on any node invalidates every node's
+// cache rather than just the writer's. It reconnects and flushes the local
+// cache on any error, since entries may have gone stale while the
+// subscription was down, mirroring watchClientTracking below.
+func subscribeCacheInvalidations() {
+	for {
+		if err := runCacheInvalidationSubscriber(); err != nil {
+			log.Printf("Cache invalidation subscription lost: %v; reconnecting", err)
+		}
+		localCache.Clear()
+		time.Sleep(time.Second)
+	}
+}
+
+func runCacheInvalidationSubscriber() error {
+	sub := currentSlave().Subscribe(ctx, cacheInvalidateChannel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		invalidateCachedKey(msg.Payload)
+	}
+
+	return fmt.Errorf("invalidation subscription closed")
+}
+
+// invalidateCachedKey evicts every cache entry derived from key, across all
+// of the cached read operations.
+func invalidateCachedKey(key string) {
+	localCache.Invalidate("get:" + key)
+	localCache.Invalidate("exists:" + key)
+	localCache.Invalidate("hgetall:" + key)
+	localCache.Invalidate("smembers:" + key)
+	localCache.Invalidate("lrange:" + key)
+}
+
+// publishInvalidate announces that key changed so every node (including this
+// one, via its own subscription) evicts it from the local cache.
+func publishInvalidate(key string) {
+	if err := currentMaster().Publish(ctx, cacheInvalidateChannel, key).Err(); err != nil {
+		log.Printf("Failed to publish cache invalidation for %q: %v", key, err)
+	}
+}
+
+// watchClientTracking is an alternative, server-assisted invalidation path:
+// instead of relying on the app-level cache:invalidate channel, it enables
+// RESP3 CLIENT TRACKING on the read connection so Redis itself pushes
+// invalidation notices whenever a tracked key changes, from any writer.
+// It reconnects and flushes the local cache on any error, since entries
+// may have gone stale while tracking was down.
+func watchClientTracking(bcast bool) {
+	for {
+		if err := runClientTracking(bcast); err != nil {
+			log.Printf("Client-side tracking connection lost: %v; reconnecting", err)
+		}
+		localCache.Clear()
+		time.Sleep(time.Second)
+	}
+}
+
+func runClientTracking(bcast bool) error {
+	// Resolve the slave once and build both connections against it: calling
+	// currentSlave() a second time would round-robin to a different node
+	// with ≥2 replicas, pinning CLIENT ID/the invalidate subscription to one
+	// server while issuing CLIENT TRACKING's REDIRECT against another, where
+	// that client ID doesn't exist.
+	slave := currentSlave()
+	addr := slave.Options().Addr
+	invConn := redis.NewClient(&redis.Options{Addr: addr})
+	defer invConn.Close()
+
+	id, err := invConn.ClientID(ctx).Result()
+	if err != nil {
+		return fmt.Errorf("CLIENT ID: %w", err)
+	}
+
+	sub := invConn.Subscribe(ctx, "__redis__:invalidate")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe to invalidate channel: %w", err)
+	}
+
+	// CLIENT TRACKING only tracks keys read on the connection it's enabled
+	// on, so pin a single dedicated connection out of the read pool for it
+	// instead of currentSlave(), which would hand the command to one
+	// arbitrary pooled connection and leave the rest of the pool untracked.
+	// Built from the same slave resolved above, not a fresh currentSlave()
+	// call, so it's guaranteed to be the same node invConn is on.
+	conn := slave.Conn()
+	defer conn.Close()
+
+	args := []interface{}{"CLIENT", "TRACKING", "ON", "REDIRECT", id}
+	if bcast {
+		args = append(args, "BCAST",
+			"PREFIX", "key:", "PREFIX", "list:", "PREFIX", "set:", "PREFIX", "hash:", "PREFIX", "counter:")
+	}
+	trackingCmd := redis.NewCmd(ctx, args...)
+	if err := conn.Process(ctx, trackingCmd); err != nil {
+		return fmt.Errorf("CLIENT TRACKING ON: %w", err)
+	}
+
+	trackedConn.Store(conn)
+	defer trackedConn.Store((*redis.Conn)(nil))
+
+	log.Printf("Client-side caching enabled via Redis CLIENT TRACKING (bcast=%v)", bcast)
+
+	for msg := range sub.Channel() {
+		if len(msg.PayloadSlice) == 0 {
+			// A nil invalidation payload means the tracking table overflowed
+			// and the server is asking us to drop everything.
+			localCache.Clear()
+			continue
+		}
+		for _, key := range msg.PayloadSlice {
+			invalidateCachedKey(key)
+		}
+	}
+
+	return fmt.Errorf("invalidation subscription closed")
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -177,7 +564,7 @@ func loggingMiddleware(next http.Handler) http.Handler {
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
-	err := masterClient.Ping(ctx).Err()
+	err := currentMaster().Ping(ctx).Err()
 	if err != nil {
 		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"status": "unhealthy",
@@ -186,7 +573,7 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = slaveClient.Ping(ctx).Err()
+	err = currentSlave().Ping(ctx).Err()
 	if err != nil {
 		respondJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
 			"status": "unhealthy",
@@ -195,12 +582,30 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"status":  "healthy",
 		"message": "Connected to Redis cluster",
-	})
+		"writeResilience": map[string]interface{}{
+			"breakerState": writeBreaker.State(),
+			"bufferDepth":  writeBuffer.Depth(),
+			"replayed":     writeBuffer.Replayed(),
+			"dropped":      writeBuffer.Dropped(),
+		},
+	}
+	if sentinelMode {
+		if t, ok := topology.Load().(Topology); ok {
+			resp["topology"] = t
+		}
+	}
+
+	respondJSON(w, http.StatusOK, resp)
 }
 
+// statsHandler reports aggregate request and cache counters. These are also
+// mirrored into the Prometheus registry as ha_redis_stats_field (metrics.go
+// init), via a live callback onto this same stats struct rather than a
+// second counter that could drift from it, so /metrics and /stats always
+// agree.
 func statsHandler(w http.ResponseWriter, r *http.Request) {
 	uptime := float64(time.Now().Unix() - stats.StartTime)
 	total := atomic.LoadInt64(&stats.TotalRequests)
@@ -226,6 +631,14 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 		"uptime":             fmt.Sprintf("%.2fs", uptime),
 		"requestsPerSecond":  fmt.Sprintf("%.2f", requestsPerSecond),
 		"successRate":        fmt.Sprintf("%.2f%%", successRate),
+		"cacheLocalHits":     atomic.LoadInt64(&stats.CacheLocalHits),
+		"cacheLocalMisses":   atomic.LoadInt64(&stats.CacheLocalMisses),
+		"cacheRedisHits":     atomic.LoadInt64(&stats.CacheRedisHits),
+		"cacheRedisMisses":   atomic.LoadInt64(&stats.CacheRedisMisses),
+		"writeBreakerState":  writeBreaker.State(),
+		"writeBufferDepth":   writeBuffer.Depth(),
+		"writeBufferReplays": writeBuffer.Replayed(),
+		"writeBufferDropped": writeBuffer.Dropped(),
 	})
 }
 
@@ -235,6 +648,10 @@ func resetStatsHandler(w http.ResponseWriter, r *http.Request) {
 	atomic.StoreInt64(&stats.FailedRequests, 0)
 	atomic.StoreInt64(&stats.Reads, 0)
 	atomic.StoreInt64(&stats.Writes, 0)
+	atomic.StoreInt64(&stats.CacheLocalHits, 0)
+	atomic.StoreInt64(&stats.CacheLocalMisses, 0)
+	atomic.StoreInt64(&stats.CacheRedisHits, 0)
+	atomic.StoreInt64(&stats.CacheRedisMisses, 0)
 	stats.StartTime = time.Now().Unix()
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -269,17 +686,17 @@ func setHandler(w http.ResponseWriter, r *http.Request) {
 		req.TTL = 300
 	}
 
-	err := masterClient.Set(ctx, req.Key, req.Value, time.Duration(req.TTL)*time.Second).Err()
+	err := resilientWrite("set", "master", func() error {
+		return currentMaster().Set(ctx, req.Key, req.Value, time.Duration(req.TTL)*time.Second).Err()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondWriteError(w, err)
 		return
 	}
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
+	publishInvalidate(req.Key)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "SET",
@@ -305,17 +722,17 @@ func incrHandler(w http.ResponseWriter, r *http.Request) {
 		req.Key = fmt.Sprintf("counter:%d", getRandomInt(1, 100))
 	}
 
-	result, err := masterClient.Incr(ctx, req.Key).Result()
+	result, err := resilientWriteT("incr", "master", func() (int64, error) {
+		return currentMaster().Incr(ctx, req.Key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondWriteError(w, err)
 		return
 	}
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
+	publishInvalidate(req.Key)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "INCR",
@@ -345,19 +762,19 @@ func lpushHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	err := masterClient.LPush(ctx, req.Key, req.Value).Err()
+	err := resilientWrite("lpush", "master", func() error {
+		return currentMaster().LPush(ctx, req.Key, req.Value).Err()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondWriteError(w, err)
 		return
 	}
 
-	masterClient.LTrim(ctx, req.Key, 0, 99)
+	currentMaster().LTrim(ctx, req.Key, 0, 99)
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
+	publishInvalidate(req.Key)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "LPUSH",
@@ -387,17 +804,17 @@ func saddHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	result, err := masterClient.SAdd(ctx, req.Key, req.Value).Result()
+	result, err := resilientWriteT("sadd", "master", func() (int64, error) {
+		return currentMaster().SAdd(ctx, req.Key, req.Value).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondWriteError(w, err)
 		return
 	}
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
+	publishInvalidate(req.Key)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "SADD",
@@ -433,17 +850,17 @@ func hsetHandler(w http.ResponseWriter, r *http.Request) {
 		req.Value = generateRandomString(20)
 	}
 
-	result, err := masterClient.HSet(ctx, req.Key, req.Field, req.Value).Result()
+	result, err := resilientWriteT("hset", "master", func() (int64, error) {
+		return currentMaster().HSet(ctx, req.Key, req.Field, req.Value).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
-		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"error":   err.Error(),
-		})
+		respondWriteError(w, err)
 		return
 	}
 
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
+	publishInvalidate(req.Key)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "HSET",
@@ -464,7 +881,25 @@ func getHandler(w http.ResponseWriter, r *http.Request) {
 		key = generateRandomKey()
 	}
 
-	value, err := slaveClient.Get(ctx, key).Result()
+	cacheKey := "get:" + key
+	if value, ok := localCache.Get(cacheKey); ok {
+		atomic.AddInt64(&stats.CacheLocalHits, 1)
+		atomic.AddInt64(&stats.SuccessfulRequests, 1)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"operation": "GET",
+			"key":       key,
+			"value":     value,
+			"found":     true,
+			"cached":    true,
+		})
+		return
+	}
+	atomic.AddInt64(&stats.CacheLocalMisses, 1)
+
+	value, err := timedOpT("get", "slave", func() (string, error) {
+		return trackedReadClient().Get(ctx, key).Result()
+	})
 	if err != nil && err != redis.Nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
@@ -474,6 +909,13 @@ func getHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err == redis.Nil {
+		atomic.AddInt64(&stats.CacheRedisMisses, 1)
+	} else {
+		atomic.AddInt64(&stats.CacheRedisHits, 1)
+		localCache.Set(cacheKey, value, cacheTTL)
+	}
+
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
@@ -481,6 +923,7 @@ func getHandler(w http.ResponseWriter, r *http.Request) {
 		"key":       key,
 		"value":     value,
 		"found":     err != redis.Nil,
+		"cached":    false,
 	})
 }
 
@@ -494,7 +937,24 @@ func existsHandler(w http.ResponseWriter, r *http.Request) {
 		key = generateRandomKey()
 	}
 
-	exists, err := slaveClient.Exists(ctx, key).Result()
+	cacheKey := "exists:" + key
+	if cached, ok := localCache.Get(cacheKey); ok {
+		atomic.AddInt64(&stats.CacheLocalHits, 1)
+		atomic.AddInt64(&stats.SuccessfulRequests, 1)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"operation": "EXISTS",
+			"key":       key,
+			"exists":    cached == "1",
+			"cached":    true,
+		})
+		return
+	}
+	atomic.AddInt64(&stats.CacheLocalMisses, 1)
+
+	exists, err := timedOpT("exists", "slave", func() (int64, error) {
+		return trackedReadClient().Exists(ctx, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
@@ -504,12 +964,20 @@ func existsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if exists == 1 {
+		atomic.AddInt64(&stats.CacheRedisHits, 1)
+	} else {
+		atomic.AddInt64(&stats.CacheRedisMisses, 1)
+	}
+	localCache.Set(cacheKey, strconv.FormatInt(exists, 10), cacheTTL)
+
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
 		"operation": "EXISTS",
 		"key":       key,
 		"exists":    exists == 1,
+		"cached":    false,
 	})
 }
 
@@ -536,7 +1004,35 @@ func lrangeHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	values, err := slaveClient.LRange(ctx, key, int64(start), int64(stop)).Result()
+	// Only the default range is cacheable; custom start/stop windows bypass
+	// the local tier since caching every possible window isn't worthwhile.
+	cacheKey := ""
+	if start == 0 && stop == 10 {
+		cacheKey = "lrange:" + key
+		if cached, ok := localCache.Get(cacheKey); ok {
+			var values []string
+			if err := json.Unmarshal([]byte(cached), &values); err == nil {
+				atomic.AddInt64(&stats.CacheLocalHits, 1)
+				atomic.AddInt64(&stats.SuccessfulRequests, 1)
+				respondJSON(w, http.StatusOK, map[string]interface{}{
+					"success":   true,
+					"operation": "LRANGE",
+					"key":       key,
+					"start":     start,
+					"stop":      stop,
+					"values":    values,
+					"count":     len(values),
+					"cached":    true,
+				})
+				return
+			}
+		}
+		atomic.AddInt64(&stats.CacheLocalMisses, 1)
+	}
+
+	values, err := timedOpT("lrange", "slave", func() ([]string, error) {
+		return trackedReadClient().LRange(ctx, key, int64(start), int64(stop)).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
@@ -546,6 +1042,17 @@ func lrangeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(values) == 0 {
+		atomic.AddInt64(&stats.CacheRedisMisses, 1)
+	} else {
+		atomic.AddInt64(&stats.CacheRedisHits, 1)
+	}
+	if cacheKey != "" {
+		if encoded, err := json.Marshal(values); err == nil {
+			localCache.Set(cacheKey, string(encoded), cacheTTL)
+		}
+	}
+
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
@@ -555,6 +1062,7 @@ func lrangeHandler(w http.ResponseWriter, r *http.Request) {
 		"stop":      stop,
 		"values":    values,
 		"count":     len(values),
+		"cached":    false,
 	})
 }
 
@@ -568,7 +1076,28 @@ func smembersHandler(w http.ResponseWriter, r *http.Request) {
 		key = fmt.Sprintf("set:%d", getRandomInt(1, 50))
 	}
 
-	members, err := slaveClient.SMembers(ctx, key).Result()
+	cacheKey := "smembers:" + key
+	if cached, ok := localCache.Get(cacheKey); ok {
+		var members []string
+		if err := json.Unmarshal([]byte(cached), &members); err == nil {
+			atomic.AddInt64(&stats.CacheLocalHits, 1)
+			atomic.AddInt64(&stats.SuccessfulRequests, 1)
+			respondJSON(w, http.StatusOK, map[string]interface{}{
+				"success":   true,
+				"operation": "SMEMBERS",
+				"key":       key,
+				"members":   members,
+				"count":     len(members),
+				"cached":    true,
+			})
+			return
+		}
+	}
+	atomic.AddInt64(&stats.CacheLocalMisses, 1)
+
+	members, err := timedOpT("smembers", "slave", func() ([]string, error) {
+		return trackedReadClient().SMembers(ctx, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
@@ -578,6 +1107,15 @@ func smembersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(members) == 0 {
+		atomic.AddInt64(&stats.CacheRedisMisses, 1)
+	} else {
+		atomic.AddInt64(&stats.CacheRedisHits, 1)
+	}
+	if encoded, err := json.Marshal(members); err == nil {
+		localCache.Set(cacheKey, string(encoded), cacheTTL)
+	}
+
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":   true,
@@ -585,6 +1123,7 @@ func smembersHandler(w http.ResponseWriter, r *http.Request) {
 		"key":       key,
 		"members":   members,
 		"count":     len(members),
+		"cached":    false,
 	})
 }
 
@@ -598,7 +1137,28 @@ func hgetallHandler(w http.ResponseWriter, r *http.Request) {
 		key = fmt.Sprintf("hash:%d", getRandomInt(1, 50))
 	}
 
-	hash, err := slaveClient.HGetAll(ctx, key).Result()
+	cacheKey := "hgetall:" + key
+	if cached, ok := localCache.Get(cacheKey); ok {
+		var hash map[string]string
+		if err := json.Unmarshal([]byte(cached), &hash); err == nil {
+			atomic.AddInt64(&stats.CacheLocalHits, 1)
+			atomic.AddInt64(&stats.SuccessfulRequests, 1)
+			respondJSON(w, http.StatusOK, map[string]interface{}{
+				"success":    true,
+				"operation":  "HGETALL",
+				"key":        key,
+				"hash":       hash,
+				"fieldCount": len(hash),
+				"cached":     true,
+			})
+			return
+		}
+	}
+	atomic.AddInt64(&stats.CacheLocalMisses, 1)
+
+	hash, err := timedOpT("hgetall", "slave", func() (map[string]string, error) {
+		return trackedReadClient().HGetAll(ctx, key).Result()
+	})
 	if err != nil {
 		atomic.AddInt64(&stats.FailedRequests, 1)
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
@@ -608,6 +1168,15 @@ func hgetallHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(hash) == 0 {
+		atomic.AddInt64(&stats.CacheRedisMisses, 1)
+	} else {
+		atomic.AddInt64(&stats.CacheRedisHits, 1)
+	}
+	if encoded, err := json.Marshal(hash); err == nil {
+		localCache.Set(cacheKey, string(encoded), cacheTTL)
+	}
+
 	atomic.AddInt64(&stats.SuccessfulRequests, 1)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success":    true,
@@ -615,100 +1184,69 @@ func hgetallHandler(w http.ResponseWriter, r *http.Request) {
 		"key":        key,
 		"hash":       hash,
 		"fieldCount": len(hash),
+		"cached":     false,
 	})
 }
 
 func loadHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Operations     int `json:"operations"`
-		ReadWriteRatio int `json:"readWriteRatio"`
+		Operations     int            `json:"operations"`
+		Concurrency    int            `json:"concurrency"`
+		PipelineSize   int            `json:"pipelineSize"`
+		ReadWriteRatio int            `json:"readWriteRatio"`
+		OpMix          map[string]int `json:"opMix"`
+		KeyspaceSize   int            `json:"keyspaceSize"`
+		ValueSize      int            `json:"valueSize"`
+		Duration       int            `json:"duration"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		req.Operations = 100
-		req.ReadWriteRatio = 70
-	}
+	// Tolerate a missing/invalid body; the zero values get defaulted below,
+	// matching how the other handlers treat decode failures.
+	json.NewDecoder(r.Body).Decode(&req)
 
-	if req.Operations == 0 {
-		req.Operations = 100
+	if req.Operations <= 0 {
+		req.Operations = 1000
+	}
+	if req.Concurrency <= 0 {
+		req.Concurrency = 4
+	}
+	if req.PipelineSize <= 0 {
+		req.PipelineSize = 10
 	}
-	if req.ReadWriteRatio == 0 {
+	if req.ReadWriteRatio <= 0 {
 		req.ReadWriteRatio = 70
 	}
-
-	results := map[string]int{
-		"requested":  req.Operations,
-		"completed":  0,
-		"successful": 0,
-		"failed":     0,
-		"reads":      0,
-		"writes":     0,
-	}
-
-	writeOps := []string{"set", "incr", "lpush", "sadd", "hset"}
-	readOps := []string{"get", "exists", "lrange", "smembers", "hgetall"}
-
-	for i := 0; i < req.Operations; i++ {
-		isRead := rand.Intn(100) < req.ReadWriteRatio
-
-		var err error
-		if isRead {
-			results["reads"]++
-			op := readOps[rand.Intn(len(readOps))]
-			switch op {
-			case "get":
-				_, err = slaveClient.Get(ctx, generateRandomKey()).Result()
-				if err == redis.Nil {
-					err = nil
-				}
-			case "exists":
-				_, err = slaveClient.Exists(ctx, generateRandomKey()).Result()
-			case "lrange":
-				_, err = slaveClient.LRange(ctx, fmt.Sprintf("list:%d", getRandomInt(1, 50)), 0, 10).Result()
-			case "smembers":
-				_, err = slaveClient.SMembers(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50))).Result()
-			case "hgetall":
-				_, err = slaveClient.HGetAll(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50))).Result()
-			}
-		} else {
-			results["writes"]++
-			op := writeOps[rand.Intn(len(writeOps))]
-			switch op {
-			case "set":
-				err = masterClient.Set(ctx, generateRandomKey(), generateRandomString(20), 300*time.Second).Err()
-			case "incr":
-				_, err = masterClient.Incr(ctx, fmt.Sprintf("counter:%d", getRandomInt(1, 100))).Result()
-			case "lpush":
-				listKey := fmt.Sprintf("list:%d", getRandomInt(1, 50))
-				err = masterClient.LPush(ctx, listKey, generateRandomString(20)).Err()
-				if err == nil {
-					masterClient.LTrim(ctx, listKey, 0, 99)
-				}
-			case "sadd":
-				_, err = masterClient.SAdd(ctx, fmt.Sprintf("set:%d", getRandomInt(1, 50)), generateRandomString(20)).Result()
-			case "hset":
-				_, err = masterClient.HSet(ctx, fmt.Sprintf("hash:%d", getRandomInt(1, 50)), generateRandomString(10), generateRandomString(20)).Result()
-			}
-		}
-
-		if err != nil {
-			results["failed"]++
-		} else {
-			results["successful"]++
-		}
-		results["completed"]++
+	if req.KeyspaceSize <= 0 {
+		req.KeyspaceSize = 1000
+	}
+	if req.ValueSize <= 0 {
+		req.ValueSize = 20
 	}
 
-	atomic.AddInt64(&stats.TotalRequests, int64(results["completed"]))
-	atomic.AddInt64(&stats.SuccessfulRequests, int64(results["successful"]))
-	atomic.AddInt64(&stats.FailedRequests, int64(results["failed"]))
-	atomic.AddInt64(&stats.Reads, int64(results["reads"]))
-	atomic.AddInt64(&stats.Writes, int64(results["writes"]))
+	ops, weights := buildOpMix(req.OpMix, req.ReadWriteRatio)
+	duration := time.Duration(req.Duration) * time.Second
+
+	result := runLoadBenchmark(loadConfig{
+		operations:   req.Operations,
+		concurrency:  req.Concurrency,
+		pipelineSize: req.PipelineSize,
+		keyspaceSize: req.KeyspaceSize,
+		valueSize:    req.ValueSize,
+		duration:     duration,
+		ops:          ops,
+		weights:      weights,
+	})
+
+	atomic.AddInt64(&stats.TotalRequests, result.Completed)
+	atomic.AddInt64(&stats.SuccessfulRequests, result.Successful)
+	atomic.AddInt64(&stats.FailedRequests, result.Failed)
+	atomic.AddInt64(&stats.Reads, result.Reads)
+	atomic.AddInt64(&stats.Writes, result.Writes)
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "Load generation completed",
-		"results": results,
+		"results": result,
 	})
 }
 
@@ -736,3 +1274,17 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
+// respondWriteError reports a failed resilientWrite call, using 503 for a
+// short-circuited breaker (the write was buffered for later replay, not
+// lost) and 500 for everything else.
+func respondWriteError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		status = http.StatusServiceUnavailable
+	}
+	respondJSON(w, status, map[string]interface{}{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
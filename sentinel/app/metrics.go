@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ha_redis_requests_total",
+		Help: "Total Redis operations processed, labeled by operation, result, and target.",
+	}, []string{"op", "result", "target"})
+
+	operationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ha_redis_operation_duration_seconds",
+		Help:    "Latency of Redis operations, labeled by operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"op"})
+
+	replicationLagSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ha_redis_replication_lag_seconds",
+		Help: "Estimated replication lag between master and slave, derived from master_repl_offset.",
+	})
+
+	poolConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ha_redis_pool_connections",
+		Help: "Connection pool gauge, labeled by state (total|idle|stale) and target (master|slave).",
+	}, []string{"state", "target"})
+)
+
+// init registers a GaugeFunc per stats field so the /stats aggregate and
+// cache counters are also visible in this same Prometheus registry, via a
+// live callback onto stats itself rather than a second, independently-
+// updated counter set. That keeps statsHandler and /metrics reading from
+// one underlying source of truth, so resetStatsHandler zeroing stats is all
+// it takes to zero both.
+func init() {
+	for field, load := range map[string]func() int64{
+		"total_requests":      func() int64 { return atomic.LoadInt64(&stats.TotalRequests) },
+		"successful_requests": func() int64 { return atomic.LoadInt64(&stats.SuccessfulRequests) },
+		"failed_requests":     func() int64 { return atomic.LoadInt64(&stats.FailedRequests) },
+		"reads":               func() int64 { return atomic.LoadInt64(&stats.Reads) },
+		"writes":              func() int64 { return atomic.LoadInt64(&stats.Writes) },
+		"cache_local_hits":    func() int64 { return atomic.LoadInt64(&stats.CacheLocalHits) },
+		"cache_local_misses":  func() int64 { return atomic.LoadInt64(&stats.CacheLocalMisses) },
+		"cache_redis_hits":    func() int64 { return atomic.LoadInt64(&stats.CacheRedisHits) },
+		"cache_redis_misses":  func() int64 { return atomic.LoadInt64(&stats.CacheRedisMisses) },
+	} {
+		field, load := field, load
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "ha_redis_stats_field",
+			Help:        "Mirrors one /stats counter; see the field label.",
+			ConstLabels: prometheus.Labels{"field": field},
+		}, func() float64 { return float64(load()) })
+	}
+}
+
+// timedOp runs fn, classifies its error as ok/error/redis_nil, and records
+// both the duration histogram and the request counter for op against target.
+func timedOp(op, target string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	observeOp(op, target, err, time.Since(start))
+	return err
+}
+
+// timedOpT is timedOp for calls that also return a value.
+func timedOpT[T any](op, target string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	v, err := fn()
+	observeOp(op, target, err, time.Since(start))
+	return v, err
+}
+
+func observeOp(op, target string, err error, d time.Duration) {
+	operationDuration.WithLabelValues(op).Observe(d.Seconds())
+
+	result := "ok"
+	switch {
+	case err != nil && err != redis.Nil:
+		result = "error"
+	case err == redis.Nil:
+		result = "redis_nil"
+	}
+	requestsTotal.WithLabelValues(op, result, target).Inc()
+}
+
+// watchPoolAndReplicationMetrics periodically refreshes the pool and
+// replication-lag gauges, since both require polling rather than being
+// naturally event-driven.
+func watchPoolAndReplicationMetrics(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		recordPoolStats("master", currentMaster())
+		recordPoolStats("slave", currentSlave())
+		recordReplicationLag()
+	}
+}
+
+func recordPoolStats(target string, client *redis.Client) {
+	if client == nil {
+		return
+	}
+	ps := client.PoolStats()
+	poolConnections.WithLabelValues("total", target).Set(float64(ps.TotalConns))
+	poolConnections.WithLabelValues("idle", target).Set(float64(ps.IdleConns))
+	poolConnections.WithLabelValues("stale", target).Set(float64(ps.StaleConns))
+}
+
+// recordReplicationLag reports the gap in bytes between the master's
+// replication offset and the slave's as a proxy for lag; it is not
+// converted to seconds. Best-effort: any INFO parsing failure just skips
+// this tick.
+func recordReplicationLag() {
+	masterOffset, err := masterReplOffset(currentMaster())
+	if err != nil {
+		log.Printf("Failed to read master replication offset: %v", err)
+		return
+	}
+
+	slaveOffset, err := masterReplOffset(currentSlave())
+	if err != nil {
+		log.Printf("Failed to read slave replication offset: %v", err)
+		return
+	}
+
+	if masterOffset < slaveOffset {
+		return
+	}
+	// Redis reports offsets in bytes, not seconds; without a measured
+	// throughput we report the byte gap as a proxy for lag. Operators with
+	// real capacity numbers should interpret this relatively over time
+	// rather than as a literal wall-clock second count.
+	replicationLagSeconds.Set(float64(masterOffset - slaveOffset))
+}
+
+func masterReplOffset(client *redis.Client) (int64, error) {
+	info, err := client.Info(ctx, "replication").Result()
+	if err != nil {
+		return 0, err
+	}
+	return parseReplOffset(info)
+}
+
+// parseReplOffset extracts master_repl_offset from an `INFO replication`
+// response. Present on both masters and replicas (a replica reports its own
+// applied offset under the same field).
+func parseReplOffset(info string) (int64, error) {
+	for _, line := range strings.Split(info, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "master_repl_offset:"); ok {
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("master_repl_offset not found in INFO output")
+}
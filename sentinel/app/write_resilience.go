@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/elimelt/ha-redis/resilience"
+)
+
+var (
+	writeBreaker = resilience.NewBreaker(envInt("WRITE_BREAKER_THRESHOLD", 5), time.Duration(envInt("WRITE_BREAKER_COOLDOWN_SECONDS", 10))*time.Second)
+	writeBuffer  = resilience.NewWriteAheadBuffer(envInt("WRITE_BUFFER_SIZE", 1000))
+)
+
+// resilientWrite wraps a masterClient mutation with retry, a circuit
+// breaker, and a write-ahead buffer: retryable errors are retried with
+// backoff, any call that still fails after its retries is counted against
+// the breaker and buffered for replay once the breaker closes, and calls
+// made while the breaker is open are
+// buffered immediately without touching the master at all.
+func resilientWrite(op, target string, fn func() error) error {
+	if !writeBreaker.Allow() {
+		writeBuffer.Push(func() error { return timedOp(op, target, fn) })
+		return resilience.ErrCircuitOpen
+	}
+
+	err := resilience.Retry(resilience.DefaultRetryConfig, isRetryableWriteErr, func() error {
+		return timedOp(op, target, fn)
+	})
+	if err != nil {
+		writeBreaker.RecordFailure()
+		writeBuffer.Push(func() error { return timedOp(op, target, fn) })
+		return err
+	}
+
+	writeBreaker.RecordSuccess()
+	writeBuffer.TriggerReplay()
+	return nil
+}
+
+// resilientWriteT is resilientWrite for calls that also return a value.
+func resilientWriteT[T any](op, target string, fn func() (T, error)) (T, error) {
+	var zero T
+
+	if !writeBreaker.Allow() {
+		writeBuffer.Push(func() error { _, err := timedOpT(op, target, fn); return err })
+		return zero, resilience.ErrCircuitOpen
+	}
+
+	var result T
+	err := resilience.Retry(resilience.DefaultRetryConfig, isRetryableWriteErr, func() error {
+		v, err := timedOpT(op, target, fn)
+		result = v
+		return err
+	})
+	if err != nil {
+		writeBreaker.RecordFailure()
+		writeBuffer.Push(func() error { _, err := timedOpT(op, target, fn); return err })
+		return zero, err
+	}
+
+	writeBreaker.RecordSuccess()
+	writeBuffer.TriggerReplay()
+	return result, nil
+}
+
+// isRetryableWriteErr reports whether err is the kind of transient failure
+// a retry can plausibly fix: connection-level timeouts/EOF, or a RESP error
+// indicating the server is mid-failover or temporarily unwritable.
+func isRetryableWriteErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "LOADING") ||
+		strings.Contains(msg, "MASTERDOWN") ||
+		strings.Contains(msg, "READONLY")
+}
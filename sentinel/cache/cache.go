@@ -0,0 +1,151 @@
+// Package cache implements a small in-process LRU used as a first-tier read
+// cache in front of Redis. Entries carry a TTL on top of LRU eviction, and
+// callers are expected to invalidate keys explicitly on write (see
+// Invalidate) rather than rely on TTL alone, since the authoritative copy
+// lives in Redis.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds hit/miss counters for the local tier. Safe for concurrent use.
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+type entry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+	size      int
+}
+
+// Cache is a thread-safe, size- and entry-bounded LRU with per-entry TTL.
+type Cache struct {
+	mu         sync.Mutex
+	ll         *list.List
+	items      map[string]*list.Element
+	maxEntries int
+	maxBytes   int
+	curBytes   int
+
+	hits   int64
+	misses int64
+}
+
+// New creates a Cache bounded by maxEntries (0 = unlimited count) and
+// maxBytes of total value size (0 = unlimited size).
+func New(maxEntries, maxBytes int) *Cache {
+	return &Cache{
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+	}
+}
+
+// Get returns the cached value for key if present and not expired.
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		atomic.AddInt64(&c.misses, 1)
+		return "", false
+	}
+
+	e := el.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		c.removeElement(el)
+		atomic.AddInt64(&c.misses, 1)
+		return "", false
+	}
+
+	c.ll.MoveToFront(el)
+	atomic.AddInt64(&c.hits, 1)
+	return e.value, true
+}
+
+// Set inserts or updates key with the given TTL, evicting the least
+// recently used entries if the cache exceeds its configured bounds.
+func (c *Cache) Set(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		c.curBytes += len(value) - e.size
+		e.value = value
+		e.size = len(value)
+		e.expiresAt = time.Now().Add(ttl)
+		c.ll.MoveToFront(el)
+	} else {
+		e := &entry{key: key, value: value, size: len(value), expiresAt: time.Now().Add(ttl)}
+		el := c.ll.PushFront(e)
+		c.items[key] = el
+		c.curBytes += e.size
+	}
+
+	for c.overCapacity() {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+	}
+}
+
+// Invalidate evicts key from the cache, if present. Intended to be called
+// both for local writes and on receipt of a remote invalidation message.
+func (c *Cache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Clear evicts every entry. Used when the cache's coherence guarantee is
+// interrupted, e.g. a client-side tracking connection dropping and being
+// re-established, where entries may have gone stale in the gap.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.curBytes = 0
+}
+
+// Stats returns a snapshot of the hit/miss counters.
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+}
+
+func (c *Cache) overCapacity() bool {
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		return true
+	}
+	if c.maxBytes > 0 && c.curBytes > c.maxBytes {
+		return true
+	}
+	return false
+}
+
+// removeElement removes el from the LRU. Callers must hold c.mu.
+func (c *Cache) removeElement(el *list.Element) {
+	e := el.Value.(*entry)
+	c.ll.Remove(el)
+	delete(c.items, e.key)
+	c.curBytes -= e.size
+}